@@ -0,0 +1,162 @@
+package zouwu
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+const (
+	// mountParam is the catch-all parameter of the routes registered by Mount
+	mountParam = "mountpath"
+	// mountPrefixKey is the user value holding the prefixes stripped by Mount,
+	// the redirects of the router are prefixed with it
+	mountPrefixKey = "zouwu.mountprefix"
+)
+
+// Mount delegates every request under relativePath to sub, the prefix is stripped from the
+// request path before sub routes it and restored once sub has handled it.
+//     api := zouwu.NewServer()
+//     api.GET("/users/:id", getUser)
+//     router.Mount("/api", api) // GET /api/users/1 is handled by api as GET /users/1
+// The middleware of the group runs before sub, the routes are registered for the methods of Any.
+// The handlers of sub see a copy of the Keys set so far and the deadline of the outer route.
+func (group *RouterGroup) Mount(relativePath string, sub *Engine) IRoutes {
+	if strings.ContainsAny(relativePath, ":*<") {
+		panic("URL parameters can not be used when mounting an engine")
+	}
+	if sub == nil || sub == group.engine {
+		panic("[zouwu Engine]: invalid engine to mount")
+	}
+	relativePath = strings.TrimSuffix(relativePath, "/")
+	prefix := strings.TrimSuffix(group.calculateAbsolutePath(relativePath), "/")
+	handler := func(c *Context) error {
+		uri := c.Ctx.URI()
+		original := append([]byte(nil), uri.PathOriginal()...)
+		uri.SetPath(mountedPath(original, prefix, c.Params.ByName(mountParam)))
+		outer := mountPrefix(c.Ctx)
+		c.Ctx.SetUserValue(mountPrefixKey, outer+prefix)
+		subCtx := sub.AcquireCtx(c.Ctx)
+		c.mu.RLock()
+		for key, value := range c.Keys {
+			subCtx.Set(key, value)
+		}
+		c.mu.RUnlock()
+		subCtx.parent = c.timeoutCtx
+		sub.prepareHandler(subCtx)
+		subCtx.Next()
+		sub.ReleaseCtx(subCtx)
+		c.Ctx.SetUserValue(mountPrefixKey, outer)
+		uri.SetPathBytes(original)
+		return nil
+	}
+	if prefix != "" {
+		group.Any(relativePath, handler)
+	}
+	group.Any(relativePath+"/*"+mountParam, handler)
+	return group.returnObj()
+}
+
+// mountedPath return the raw request path without the mount prefix, the unescaped
+// catch-all value is only used when the raw path does not start with the prefix.
+func mountedPath(rawPath []byte, prefix, value string) string {
+	if bytes.HasPrefix(rawPath, []byte(prefix)) {
+		rest := string(rawPath[len(prefix):])
+		if rest == "" {
+			return "/"
+		}
+		if rest[0] == '/' {
+			return rest
+		}
+	}
+	if !strings.HasPrefix(value, "/") {
+		value = "/" + value
+	}
+	return value
+}
+
+// mountPrefix return the prefix stripped by the mounts the request went through
+func mountPrefix(rctx *fasthttp.RequestCtx) string {
+	prefix, _ := rctx.UserValue(mountPrefixKey).(string)
+	return prefix
+}
+
+// WrapHandler converts a net/http Handler to a HandlerFunc, e.g. to serve pprof or promhttp:
+//     router.GET("/metrics", zouwu.WrapHandler(promhttp.Handler()))
+// The handler receives the full request path.
+func WrapHandler(h http.Handler) HandlerFunc {
+	handler := fasthttpadaptor.NewFastHTTPHandler(h)
+	return func(c *Context) error {
+		handler(c.Ctx)
+		return nil
+	}
+}
+
+// WrapHandlerFunc converts a net/http HandlerFunc to a HandlerFunc.
+func WrapHandlerFunc(f http.HandlerFunc) HandlerFunc {
+	return WrapHandler(f)
+}
+
+// ServeHTTP makes the Engine a net/http Handler, so it can be tested with net/http/httptest
+// or served by net/http. The request body is read in full and the response is buffered.
+func (engine *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req fasthttp.Request
+	req.Header.SetMethod(r.Method)
+	req.SetRequestURI(r.URL.RequestURI())
+	req.Header.SetHost(r.Host)
+	for key, values := range r.Header {
+		if http.CanonicalHeaderKey(key) == HeaderCookie {
+			req.Header.Set(key, strings.Join(values, "; "))
+			continue
+		}
+		for i, value := range values {
+			if i == 0 {
+				req.Header.Set(key, value)
+			} else {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.SetBody(body)
+		req.Header.SetContentLength(len(body))
+	}
+
+	var rctx fasthttp.RequestCtx
+	rctx.Init(&req, remoteTCPAddr(r.RemoteAddr), nil)
+	engine.handler(&rctx)
+
+	body := rctx.Response.Body()
+	header := w.Header()
+	rctx.Response.Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+	if header.Get(HeaderContentLength) == "" {
+		header.Set(HeaderContentLength, strconv.Itoa(len(body)))
+	}
+	w.WriteHeader(rctx.Response.StatusCode())
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// remoteTCPAddr parses the "ip:port" RemoteAddr of net/http requests
+func remoteTCPAddr(addr string) net.Addr {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil
+	}
+	p, _ := strconv.Atoi(port)
+	return &net.TCPAddr{IP: net.ParseIP(host), Port: p}
+}
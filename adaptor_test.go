@@ -0,0 +1,117 @@
+package zouwu
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMount(t *testing.T) {
+	api := NewServer()
+	api.GET("/", func(c *Context) error { return c.String("api root") })
+	api.GET("/users/:id", func(c *Context) error {
+		return c.String(string(c.Ctx.Path()) + " " + c.URLParam("id"))
+	})
+	api.RedirectTrailingSlash = true
+	api.GET("/dir/", ok)
+	api.GET("/keys", func(c *Context) error {
+		if _, ok := c.Deadline(); !ok {
+			t.Error("mounted route has no deadline")
+		}
+		c.Set("inner", true)
+		return c.String(c.GetString("user"))
+	})
+
+	e := NewServer()
+	e.GET("/users/:id", func(c *Context) error { return c.String("outer") })
+	v1 := e.Group("/v1", func(c *Context) error {
+		c.Set("user", "lily")
+		err := c.Next()
+		if _, ok := c.Get("inner"); ok {
+			t.Error("Keys set by the mounted engine leaked to the outer Context")
+		}
+		return err
+	})
+	v1.Mount("/api", api)
+	e.SetMethodConfig("/v1/api/*mountpath", &MethodConfig{Timeout: time.Second})
+
+	tests := []struct {
+		path     string
+		code     int
+		body     string
+		location string
+	}{
+		{"/v1/api/users/42", http.StatusOK, "/users/42 42", ""},
+		{"/v1/api", http.StatusOK, "api root", ""},
+		{"/v1/api/", http.StatusOK, "api root", ""},
+		{"/users/42", http.StatusOK, "outer", ""},
+		{"/v1/api/missing", http.StatusNotFound, "", ""},
+		{"/v1/api/keys", http.StatusOK, "lily", ""},
+		// redirects of the mounted engine keep the mount prefix
+		{"/v1/api/dir?x=1", http.StatusMovedPermanently, "", "/v1/api/dir/?x=1"},
+	}
+	for _, tt := range tests {
+		resp := performRequest(e, newRequest(http.MethodGet, tt.path))
+		if resp.StatusCode() != tt.code {
+			t.Errorf("GET %s: status = %d, want %d", tt.path, resp.StatusCode(), tt.code)
+		}
+		if body := string(resp.Body()); tt.body != "" && body != tt.body {
+			t.Errorf("GET %s: body = %q, want %q", tt.path, body, tt.body)
+		}
+		if got := string(resp.Header.Peek(HeaderLocation)); got != tt.location {
+			t.Errorf("GET %s: Location = %q, want %q", tt.path, got, tt.location)
+		}
+	}
+}
+
+func TestWrapHandler(t *testing.T) {
+	e := NewServer()
+	e.POST("/std/*path", WrapHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Path", r.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, r.URL.Query().Get("q")+" "+string(body))
+	}))
+
+	req := newRequest(http.MethodPost, "/std/a/b?q=1")
+	req.Header.SetContentType("text/plain")
+	req.SetBodyString("body")
+	resp := performRequest(e, req)
+	if resp.StatusCode() != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", resp.StatusCode(), http.StatusAccepted)
+	}
+	if got := string(resp.Header.Peek("X-Path")); got != "/std/a/b" {
+		t.Errorf("X-Path = %q, want %q", got, "/std/a/b")
+	}
+	if got := string(resp.Body()); got != "1 body" {
+		t.Errorf("body = %q, want %q", got, "1 body")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	e := NewServer()
+	e.POST("/users/:id", func(c *Context) error {
+		c.Ctx.Response.Header.Set("X-Cookie", string(c.Ctx.Request.Header.Cookie("a"))+string(c.Ctx.Request.Header.Cookie("b")))
+		c.Status(http.StatusCreated)
+		return c.String(c.URLParam("id") + " " + string(c.GetRequestBody()))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42", strings.NewReader("body"))
+	req.AddCookie(&http.Cookie{Name: "a", Value: "1"})
+	req.AddCookie(&http.Cookie{Name: "b", Value: "2"})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if got := rec.Header().Get("X-Cookie"); got != "12" {
+		t.Errorf("X-Cookie = %q, want %q", got, "12")
+	}
+	if got := rec.Body.String(); got != "42 body" {
+		t.Errorf("body = %q, want %q", got, "42 body")
+	}
+}
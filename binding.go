@@ -0,0 +1,329 @@
+package zouwu
+
+import (
+	"encoding/xml"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Binder decodes the incoming request into obj.
+// Register custom Binder with Engine.RegisterBinder to support more content types (msgpack, protobuf...).
+type Binder interface {
+	Name() string
+	Bind(ctx *Context, obj interface{}) error
+}
+
+// Built-in binders
+var (
+	JSONBinder          Binder = jsonBinding{}
+	XMLBinder           Binder = xmlBinding{}
+	FormBinder          Binder = formBinding{}
+	MultipartFormBinder Binder = multipartFormBinding{}
+	QueryBinder         Binder = queryBinding{}
+	HeaderBinder        Binder = headerBinding{}
+	URIBinder           Binder = uriBinding{}
+)
+
+// defaultBinders return content type to binder mapping used by Bind
+func defaultBinders() map[string]Binder {
+	return map[string]Binder{
+		MIMEApplicationJSON: JSONBinder,
+		MIMEApplicationXML:  XMLBinder,
+		MIMETextXML:         XMLBinder,
+		MIMEApplicationForm: FormBinder,
+		MIMEMultipartForm:   MultipartFormBinder,
+	}
+}
+
+type jsonBinding struct{}
+
+func (jsonBinding) Name() string {
+	return "json"
+}
+
+func (jsonBinding) Bind(ctx *Context, obj interface{}) error {
+	return json.Unmarshal(ctx.GetRequestBody(), obj)
+}
+
+type xmlBinding struct{}
+
+func (xmlBinding) Name() string {
+	return "xml"
+}
+
+func (xmlBinding) Bind(ctx *Context, obj interface{}) error {
+	return xml.Unmarshal(ctx.GetRequestBody(), obj)
+}
+
+type formBinding struct{}
+
+func (formBinding) Name() string {
+	return "form"
+}
+
+func (formBinding) Bind(ctx *Context, obj interface{}) error {
+	args := ctx.Ctx.PostArgs()
+	return mapValues(obj, "form", func(key string) []string {
+		return bytesToStrings(args.PeekMulti(key))
+	}, nil)
+}
+
+type multipartFormBinding struct{}
+
+func (multipartFormBinding) Name() string {
+	return "multipart/form-data"
+}
+
+func (multipartFormBinding) Bind(ctx *Context, obj interface{}) error {
+	form, err := ctx.Ctx.MultipartForm()
+	if err != nil {
+		return err
+	}
+	return mapValues(obj, "form", func(key string) []string {
+		return form.Value[key]
+	}, func(key string) []*multipart.FileHeader {
+		return form.File[key]
+	})
+}
+
+type queryBinding struct{}
+
+func (queryBinding) Name() string {
+	return "query"
+}
+
+func (queryBinding) Bind(ctx *Context, obj interface{}) error {
+	args := ctx.Ctx.QueryArgs()
+	return mapValues(obj, "query", func(key string) []string {
+		return bytesToStrings(args.PeekMulti(key))
+	}, nil)
+}
+
+type headerBinding struct{}
+
+func (headerBinding) Name() string {
+	return "header"
+}
+
+func (headerBinding) Bind(ctx *Context, obj interface{}) error {
+	header := &ctx.Ctx.Request.Header
+	return mapValues(obj, "header", func(key string) []string {
+		if value := header.Peek(key); value != nil {
+			return []string{string(value)}
+		}
+		return nil
+	}, nil)
+}
+
+type uriBinding struct{}
+
+func (uriBinding) Name() string {
+	return "uri"
+}
+
+func (uriBinding) Bind(ctx *Context, obj interface{}) error {
+	return mapValues(obj, "uri", func(key string) []string {
+		if value, ok := ctx.Params.Get(key); ok {
+			return []string{value}
+		}
+		return nil
+	}, nil)
+}
+
+func bytesToStrings(values [][]byte) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	ss := make([]string, len(values))
+	for i, v := range values {
+		ss[i] = string(v)
+	}
+	return ss
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+// mapValues fill the fields of struct pointer obj from values looked up by the tag name,
+// the field name is used when the tag is missing. Fields tagged "-" are skipped.
+func mapValues(obj interface{}, tag string, values func(key string) []string, files func(key string) []*multipart.FileHeader) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("[zouwu Binding]: %s binding requires a struct pointer, got %T", tag, obj)
+	}
+	return mapStruct(rv.Elem(), tag, values, files)
+}
+
+func mapStruct(rv reflect.Value, tag string, values func(key string) []string, files func(key string) []*multipart.FileHeader) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		name := sf.Tag.Get(tag)
+		if name == "-" {
+			continue
+		}
+		field := rv.Field(i)
+		if sf.Anonymous && name == "" {
+			// embedded structs are flattened, a nil *T is allocated first
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				if sf.Type.Kind() == reflect.Ptr {
+					if field.IsNil() {
+						if !field.CanSet() {
+							continue
+						}
+						field.Set(reflect.New(ft))
+					}
+					field = field.Elem()
+				}
+				if err := mapStruct(field, tag, values, files); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if name == "" && sf.Type.Kind() == reflect.Struct && sf.Type != timeType {
+			if err := mapStruct(field, tag, values, files); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		if files != nil {
+			if fhs := files(name); len(fhs) > 0 {
+				switch {
+				case sf.Type == fileHeaderType:
+					field.Set(reflect.ValueOf(fhs[0]))
+					continue
+				case sf.Type.Kind() == reflect.Slice && sf.Type.Elem() == fileHeaderType:
+					field.Set(reflect.ValueOf(fhs))
+					continue
+				}
+			}
+		}
+
+		vs := values(name)
+		if len(vs) == 0 {
+			continue
+		}
+		if err := setField(field, sf, vs); err != nil {
+			return errors.Wrapf(err, "[zouwu Binding]: field %s", sf.Name)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, sf reflect.StructField, vs []string) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(vs), len(vs))
+		for i, v := range vs {
+			if err := setValue(slice.Index(i), sf, v); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	case reflect.Array:
+		if len(vs) != field.Len() {
+			return errors.Errorf("%q is not valid value for %s", vs, field.Type())
+		}
+		for i, v := range vs {
+			if err := setValue(field.Index(i), sf, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return setValue(field, sf, vs[0])
+}
+
+func setValue(field reflect.Value, sf reflect.StructField, val string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setValue(field.Elem(), sf, val)
+	}
+	if val == "" && field.Kind() != reflect.String {
+		return nil
+	}
+
+	switch field.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case time.Time:
+		layout := sf.Tag.Get("time_format")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, val)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return errors.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// contentType return the request mime type without parameters
+func contentType(ctx *Context) string {
+	ct := string(ctx.Ctx.Request.Header.ContentType())
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(ct))
+}
@@ -0,0 +1,160 @@
+package zouwu
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Paging is exported so encoding/json can allocate it when embedded as a pointer
+type Paging struct {
+	Page int `json:"page" xml:"page" form:"page" query:"page"`
+}
+
+type bindingUser struct {
+	*Paging
+	Name    string   `json:"name" xml:"name" form:"name" query:"name" uri:"name"`
+	Age     int      `json:"age" xml:"age" form:"age" query:"age" uri:"age"`
+	Tags    []string `json:"tags" xml:"tags" form:"tags" query:"tags"`
+	Skipped string   `json:"-" xml:"-" form:"-" query:"-" uri:"-"`
+	secret  string
+}
+
+func TestBind(t *testing.T) {
+	multipartBody := new(bytes.Buffer)
+	mw := multipart.NewWriter(multipartBody)
+	mw.WriteField("name", "lily")
+	mw.WriteField("age", "18")
+	mw.WriteField("page", "2")
+	mw.WriteField("tags", "a")
+	mw.WriteField("tags", "b")
+	mw.Close()
+
+	want := bindingUser{Paging: &Paging{Page: 2}, Name: "lily", Age: 18, Tags: []string{"a", "b"}}
+	tests := []struct {
+		name        string
+		method      string
+		uri         string
+		contentType string
+		body        string
+		want        bindingUser
+	}{
+		{"json", http.MethodPost, "/bind", MIMEApplicationJSON, `{"name":"lily","age":18,"page":2,"tags":["a","b"]}`, want},
+		{"json charset", http.MethodPost, "/bind", MIMEApplicationJSONCharsetUTF8, `{"name":"lily","age":18,"page":2,"tags":["a","b"]}`, want},
+		{"xml", http.MethodPost, "/bind", MIMEApplicationXML, `<user><name>lily</name><age>18</age><page>2</page><tags>a</tags><tags>b</tags></user>`, want},
+		{"form", http.MethodPost, "/bind", MIMEApplicationForm, "name=lily&age=18&page=2&tags=a&tags=b&Skipped=x&secret=x", want},
+		{"multipart", http.MethodPost, "/bind", mw.FormDataContentType(), multipartBody.String(), want},
+		{"query", http.MethodGet, "/bind?name=lily&age=18&page=2&tags=a&tags=b&Skipped=x", "", "", want},
+		{"uri", http.MethodGet, "/uri/lily/18", "", "", bindingUser{Paging: &Paging{}, Name: "lily", Age: 18}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bindingUser
+			e := NewServer()
+			e.Any("/bind", func(c *Context) error {
+				return c.Bind(&got)
+			})
+			e.GET("/uri/:name/:age", func(c *Context) error {
+				return c.BindURI(&got)
+			})
+			req := newRequest(tt.method, tt.uri)
+			if tt.contentType != "" {
+				req.Header.SetContentType(tt.contentType)
+				req.SetBodyString(tt.body)
+			}
+			resp := performRequest(e, req)
+			if resp.StatusCode() != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode(), http.StatusOK, resp.Body())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bound %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindHeader(t *testing.T) {
+	var got struct {
+		Token string `header:"X-Token"`
+		Count int    `header:"X-Count"`
+	}
+	e := NewServer()
+	e.GET("/header", func(c *Context) error {
+		return c.BindHeader(&got)
+	})
+	req := newRequest(http.MethodGet, "/header")
+	req.Header.Set("X-Token", "abc")
+	req.Header.Set("X-Count", "3")
+	if resp := performRequest(e, req); resp.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode(), http.StatusOK)
+	}
+	if got.Token != "abc" || got.Count != 3 {
+		t.Errorf("bound %+v", got)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+	}{
+		{"unsupported media type", "application/msgpack", "", http.StatusUnsupportedMediaType},
+		{"missing content type", "", "name=lily", http.StatusUnsupportedMediaType},
+		{"bad json", MIMEApplicationJSON, `{"name":`, http.StatusBadRequest},
+		{"bad xml", MIMEApplicationXML, `<user><name>`, http.StatusBadRequest},
+		{"bad form value", MIMEApplicationForm, "age=old", http.StatusBadRequest},
+		{"bad multipart", "multipart/form-data; boundary=x", "garbage", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewServer()
+			e.POST("/bind", func(c *Context) error {
+				var u bindingUser
+				if err := c.Bind(&u); err != nil {
+					return err
+				}
+				return c.String("ok")
+			})
+			req := newRequest(http.MethodPost, "/bind")
+			req.Header.SetContentType(tt.contentType)
+			req.SetBodyString(tt.body)
+			if resp := performRequest(e, req); resp.StatusCode() != tt.code {
+				t.Errorf("status = %d, want %d: %s", resp.StatusCode(), tt.code, resp.Body())
+			}
+		})
+	}
+}
+
+type msgBinding struct{}
+
+func (msgBinding) Name() string {
+	return "msg"
+}
+
+func (msgBinding) Bind(ctx *Context, obj interface{}) error {
+	obj.(*bindingUser).Name = strings.ToUpper(string(ctx.GetRequestBody()))
+	return nil
+}
+
+func TestRegisterBinder(t *testing.T) {
+	var got bindingUser
+	e := NewServer()
+	e.RegisterBinder("Application/X-Msg", msgBinding{})
+	e.POST("/bind", func(c *Context) error {
+		return c.Bind(&got)
+	})
+	req := newRequest(http.MethodPost, "/bind")
+	req.Header.SetContentType("application/x-msg; charset=utf-8")
+	req.SetBodyString("lily")
+	if resp := performRequest(e, req); resp.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode(), http.StatusOK)
+	}
+	if got.Name != "LILY" {
+		t.Errorf("Name = %q, want %q", got.Name, "LILY")
+	}
+}
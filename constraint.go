@@ -0,0 +1,97 @@
+package zouwu
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// paramConstraints are the named constraints usable in route params, e.g. /users/:id<int>.
+// Any other constraint is compiled as a regular expression matching the whole segment,
+// e.g. /files/:name<[a-z0-9-]+>. Constraints can not contain '/'.
+// Params with different constraints can share a segment, e.g. /users/:id<int> and /users/:name<alpha>:
+// they are tried in registration order, an unconstrained param last, until one matches the whole path.
+var paramConstraints = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[a-zA-Z]+`,
+	"alnum": `[a-zA-Z0-9]+`,
+	"hex":   `[0-9a-fA-F]+`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// splitConstraint splits the wildcard ":name<constraint>" into ":name" and "constraint".
+func splitConstraint(wildcard, fullPath string) (name, constraint string) {
+	start := strings.IndexByte(wildcard, '<')
+	if start < 0 {
+		return wildcard, ""
+	}
+	if wildcard[len(wildcard)-1] != '>' {
+		panic("param constraint must end the path segment in path '" + fullPath + "'")
+	}
+	if wildcard[0] != ':' {
+		panic("constraints are only allowed on named params in path '" + fullPath + "'")
+	}
+	return wildcard[:start], wildcard[start+1 : len(wildcard)-1]
+}
+
+// compiledConstraints caches the regexps of compileConstraint, they are shared by the routes
+// and reused by Engine.URL
+var compiledConstraints sync.Map
+
+// compileConstraint return the regexp matching the whole param value
+func compileConstraint(constraint, fullPath string) *regexp.Regexp {
+	if constraint == "" {
+		return nil
+	}
+	if re, ok := compiledConstraints.Load(constraint); ok {
+		return re.(*regexp.Regexp)
+	}
+	pattern := constraint
+	if named, ok := paramConstraints[constraint]; ok {
+		pattern = named
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		panic("invalid param constraint '" + pattern + "' in path '" + fullPath + "': " + err.Error())
+	}
+	compiledConstraints.Store(constraint, re)
+	return re
+}
+
+// expandOptional return the paths registered for a route ending with optional params,
+// e.g. /users/:id? registers /users and /users/:id.
+func expandOptional(path string) []string {
+	segments := strings.Split(path, "/")
+	first := -1
+	for i, segment := range segments {
+		optional := isOptionalSegment(segment)
+		if optional && first < 0 {
+			first = i
+		}
+		if !optional && first >= 0 {
+			panic("optional params must be the last path segments in path '" + path + "'")
+		}
+	}
+	if first < 0 {
+		return []string{path}
+	}
+
+	paths := make([]string, 0, len(segments)-first+1)
+	for end := first; end <= len(segments); end++ {
+		parts := make([]string, end)
+		for i := range parts {
+			parts[i] = strings.TrimSuffix(segments[i], "?")
+		}
+		p := strings.Join(parts, "/")
+		if p == "" {
+			p = "/"
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func isOptionalSegment(segment string) bool {
+	return len(segment) > 2 && segment[0] == ':' && segment[len(segment)-1] == '?'
+}
@@ -1,7 +1,9 @@
 package zouwu
 
 import (
+	"context"
 	"math"
+	"net/http"
 	"sync"
 	"time"
 
@@ -14,6 +16,9 @@ const (
 	_abortIndex int8 = math.MaxInt8 / 2
 )
 
+// RequestIDKey is the key of Context.Keys holding the request ID set by the RequestID middleware.
+const RequestIDKey = "zouwu.request_id"
+
 var json = jsoniter.Config{
 	EscapeHTML:             true,
 	SortMapKeys:            true,
@@ -44,7 +49,12 @@ type Context struct {
 	RoutePath string
 
 	Params Params
-	err    error
+
+	// timeoutCtx carries the deadline of the matched route, see Engine.SetMethodConfig.
+	timeoutCtx context.Context
+	cancel     context.CancelFunc
+	// parent is the deadline of the outer engine when mounted, see RouterGroup.Mount.
+	parent context.Context
 }
 
 /************************************/
@@ -58,8 +68,29 @@ func (c *Context) reset() {
 	c.Error = nil
 	c.method = ""
 	c.RoutePath = ""
-	c.err = nil
 	c.Params = c.Params[0:0]
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.timeoutCtx = nil
+	c.cancel = nil
+	c.parent = nil
+}
+
+// withTimeout starts the request deadline when timeout is positive, Done is closed once it passes.
+// The deadline of the parent, if any, still applies.
+func (c *Context) withTimeout(timeout time.Duration) {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.timeoutCtx, c.cancel = c.parent, nil
+	if timeout > 0 {
+		parent := c.parent
+		if parent == nil {
+			parent = context.Background()
+		}
+		c.timeoutCtx, c.cancel = context.WithTimeout(parent, timeout)
+	}
 }
 
 /************************************/
@@ -210,6 +241,12 @@ func (c *Context) GetStringMapStringSlice(key string) (smss map[string][]string)
 	return
 }
 
+// RequestID returns the ID of the request set by the RequestID middleware,
+// or an empty string when the middleware is not used.
+func (c *Context) RequestID() string {
+	return c.GetString(RequestIDKey)
+}
+
 /************************************/
 /************ INPUT DATA ************/
 /************************************/
@@ -391,6 +428,11 @@ func (c *Context) DefaultQueryUint(key string, defaultValue uint) uint {
 	return defaultValue
 }
 
+// URLFor builds the path of the named route, see Engine.URL.
+func (c *Context) URLFor(name string, params ...interface{}) (string, error) {
+	return c.engine.URL(name, params...)
+}
+
 // GetQuery is like Query(), it returns the keyed url query value
 // if it exists `(value, true)` (even when the value is an empty string),
 // otherwise it returns `("", false)`.
@@ -426,13 +468,80 @@ func (c *Context) GetJSONBody(dest interface{}) error {
 	return nil
 }
 
+// Bind decodes the request into obj with the Binder registered for the request Content-Type,
+// GET and HEAD requests are decoded from the query string.
+// ErrUnsupportedMediaType is returned when no Binder matches the Content-Type.
+func (c *Context) Bind(obj interface{}) error {
+	if c.Ctx.IsGet() || c.Ctx.IsHead() {
+		return c.BindWith(obj, QueryBinder)
+	}
+	b, ok := c.engine.binders[contentType(c)]
+	if !ok {
+		return ErrUnsupportedMediaType
+	}
+	return c.BindWith(obj, b)
+}
+
+// BindJSON is a shortcut for c.BindWith(obj, JSONBinder).
+func (c *Context) BindJSON(obj interface{}) error {
+	return c.BindWith(obj, JSONBinder)
+}
+
+// BindXML is a shortcut for c.BindWith(obj, XMLBinder).
+func (c *Context) BindXML(obj interface{}) error {
+	return c.BindWith(obj, XMLBinder)
+}
+
+// BindQuery is a shortcut for c.BindWith(obj, QueryBinder), fields are mapped by the `query` tag.
+func (c *Context) BindQuery(obj interface{}) error {
+	return c.BindWith(obj, QueryBinder)
+}
+
+// BindHeader is a shortcut for c.BindWith(obj, HeaderBinder), fields are mapped by the `header` tag.
+func (c *Context) BindHeader(obj interface{}) error {
+	return c.BindWith(obj, HeaderBinder)
+}
+
+// BindURI is a shortcut for c.BindWith(obj, URIBinder), fields are mapped by the `uri` tag.
+//     router.GET("/user/:id", func(c *zouwu.Context) error {
+//         var req struct {
+//             ID int64 `uri:"id"`
+//         }
+//         return c.BindURI(&req)
+//     })
+func (c *Context) BindURI(obj interface{}) error {
+	return c.BindWith(obj, URIBinder)
+}
+
+// BindWith decodes the request into obj with the given Binder and validates it by its `validate` tags.
+// A decode failure is returned as a 400 *Error and a validation failure as a *ValidationError,
+// obj implementing JSONValidator is validated afterwards.
+func (c *Context) BindWith(obj interface{}, b Binder) error {
+	if err := b.Bind(c, obj); err != nil {
+		return NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := c.engine.validator.Validate(obj); err != nil {
+		return err
+	}
+	if validate, ok := obj.(JSONValidator); ok {
+		return validate.Validate()
+	}
+	return nil
+}
+
+// Logger return the logger of the engine.
+func (c *Context) Logger() Logger {
+	return c.engine.logger
+}
+
 // Status sets the HTTP response code.
 func (c *Context) Status(code int) {
 	c.Ctx.SetStatusCode(code)
 }
 
-// Errors render error
+// Errors render error, err is kept in c.Error for the middleware that runs after the handler.
 func (c *Context) Errors(err error) error {
+	c.Error = err
 	if c.engine.errorHandler != nil {
 		c.engine.errorHandler(c, err)
 	} else {
@@ -455,6 +564,11 @@ func (c *Context) JSON(data interface{}) error {
 	return nil
 }
 
+// XML render xml
+func (c *Context) XML(data interface{}) error {
+	return c.RenderWith(XMLRenderer, data)
+}
+
 // JSON render string
 func (c *Context) String(data string) error {
 	c.Ctx.Response.Header.SetContentType(MIMETextPlainCharsetUTF8)
@@ -464,28 +578,92 @@ func (c *Context) String(data string) error {
 
 // Bytes writes some data into the body stream and updates the HTTP code.
 func (c *Context) Bytes(code int, contentType string, data []byte) {
-	c.Ctx.Response.Header.SetContentType(MIMETextPlain)
+	c.Ctx.Response.Header.SetContentType(contentType)
 	c.Ctx.Response.SetBodyRaw(data)
 	c.Status(code)
 }
 
+// HTML renders the template name loaded by Engine.LoadHTMLGlob or Engine.LoadHTMLFS and updates the HTTP code.
+func (c *Context) HTML(code int, name string, data interface{}) error {
+	raw, err := c.engine.html.Render(HTMLTemplate{Name: name, Data: data})
+	if err != nil {
+		return err
+	}
+	c.Bytes(code, MIMETextHTMLCharsetUTF8, raw)
+	return nil
+}
+
+// Negotiate return the offer best matching the Accept header, ties are won by the first offer.
+// The first offer is returned when there is no Accept header and "" when no offer is acceptable.
+//     Accept: application/xml;q=0.9, */*;q=0.1
+//     c.Negotiate("application/json", "application/xml") == "application/xml"
+func (c *Context) Negotiate(offers ...string) string {
+	return negotiate(string(c.Ctx.Request.Header.Peek(HeaderAccept)), offers)
+}
+
+// NegotiateEncoding return the offered content coding with the highest q-value in the Accept-Encoding
+// header, ties are won by the first offer. It returns "" when the response must not be encoded.
+//     Accept-Encoding: gzip;q=0.5, br
+//     c.NegotiateEncoding("gzip", "br") == "br"
+func (c *Context) NegotiateEncoding(offers ...string) string {
+	return negotiateEncoding(string(c.Ctx.Request.Header.Peek(HeaderAcceptEncoding)), offers)
+}
+
+// Render writes data with the registered Renderer negotiated against the Accept header
+// and updates the HTTP code. ErrNotAcceptable is returned when no Renderer is acceptable.
+func (c *Context) Render(code int, data interface{}) error {
+	rs := c.engine.renderers
+	c.Ctx.Response.Header.Add(HeaderVary, HeaderAccept)
+	mediaType := c.Negotiate(rs.offers...)
+	if mediaType == "" {
+		return ErrNotAcceptable
+	}
+	if err := c.RenderWith(rs.byType[mediaType], data); err != nil {
+		return err
+	}
+	c.Status(code)
+	return nil
+}
+
+// RenderWith writes data with the given Renderer.
+func (c *Context) RenderWith(r Renderer, data interface{}) error {
+	raw, err := r.Render(data)
+	if err != nil {
+		return err
+	}
+	c.Ctx.Response.Header.SetContentType(r.ContentType())
+	c.Ctx.Response.SetBodyRaw(raw)
+	return nil
+}
+
 /************************************/
 /************ context.Context ************/
 /************************************/
 
-// Deadline do nothing
+// Deadline returns the deadline of the matched route,
+// ok is false when the route has no timeout or the request has not been routed yet.
 func (c *Context) Deadline() (deadline time.Time, ok bool) {
-	return
+	if c.timeoutCtx == nil {
+		return
+	}
+	return c.timeoutCtx.Deadline()
 }
 
-// Done do nothing
+// Done returns a channel that is closed when the route timeout expires, it is nil when the route has no timeout.
+// Note that fasthttp does not report client disconnects while a handler is running.
 func (c *Context) Done() <-chan struct{} {
-	return nil
+	if c.timeoutCtx == nil {
+		return nil
+	}
+	return c.timeoutCtx.Done()
 }
 
 // Err return context error
 func (c *Context) Err() error {
-	return c.err
+	if c.timeoutCtx == nil {
+		return nil
+	}
+	return c.timeoutCtx.Err()
 }
 
 // Value try get value from key
@@ -494,5 +672,8 @@ func (c *Context) Value(key interface{}) interface{} {
 		val, _ := c.Get(keyStr)
 		return val
 	}
+	if c.timeoutCtx != nil {
+		return c.timeoutCtx.Value(key)
+	}
 	return nil
 }
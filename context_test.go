@@ -0,0 +1,104 @@
+package zouwu
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestContextTimeout(t *testing.T) {
+	e := NewServer()
+	var seen int
+	e.Use(func(c *Context) error {
+		c.Next()
+		// the timeout is reported before the middlewares unwind
+		seen = c.Ctx.Response.StatusCode()
+		return nil
+	})
+	e.GET("/default", func(c *Context) error {
+		if _, ok := c.Deadline(); ok {
+			t.Error("route without timeout has a deadline")
+		}
+		if c.Done() != nil {
+			t.Error("route without timeout has a Done channel")
+		}
+		return c.String("ok")
+	})
+	e.GET("/slow", func(c *Context) error {
+		if _, ok := c.Deadline(); !ok {
+			t.Error("route with timeout has no deadline")
+		}
+		<-c.Done()
+		if c.Err() != context.DeadlineExceeded {
+			t.Errorf("Err() = %v, want %v", c.Err(), context.DeadlineExceeded)
+		}
+		return c.String("late")
+	})
+	e.SetMethodConfig("/slow", &MethodConfig{Timeout: 10 * time.Millisecond})
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/default", http.StatusOK},
+		{"/slow", http.StatusRequestTimeout},
+	}
+	for _, tt := range tests {
+		resp := performRequest(e, newRequest(http.MethodGet, tt.path))
+		if resp.StatusCode() != tt.code {
+			t.Errorf("GET %s: status = %d, want %d", tt.path, resp.StatusCode(), tt.code)
+		}
+		if seen != tt.code {
+			t.Errorf("GET %s: middleware saw status %d, want %d", tt.path, seen, tt.code)
+		}
+	}
+}
+
+func TestContextServerTimeout(t *testing.T) {
+	e := NewServer()
+	conf := defaultServerConfig()
+	conf.Timeout = 10 * time.Millisecond
+	if err := e.SetConfig(conf); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/slow", func(c *Context) error {
+		<-c.Done()
+		return c.Err()
+	})
+	if resp := performRequest(e, newRequest(http.MethodGet, "/slow")); resp.StatusCode() != http.StatusRequestTimeout {
+		t.Errorf("status = %d, want %d", resp.StatusCode(), http.StatusRequestTimeout)
+	}
+
+	conf = defaultServerConfig()
+	conf.Timeout = -time.Second
+	if err := e.SetConfig(conf); err == nil {
+		t.Error("SetConfig() with a negative timeout = nil, want an error")
+	}
+}
+
+func TestContextNotCanceledOnShutdown(t *testing.T) {
+	e := NewServer()
+	started := make(chan struct{})
+	e.GET("/wait", func(c *Context) error {
+		close(started)
+		select {
+		case <-c.Done():
+			return c.String("canceled")
+		case <-time.After(100 * time.Millisecond):
+			return c.String("done")
+		}
+	})
+	e.SetMethodConfig("/wait", &MethodConfig{Timeout: time.Second})
+	url, _ := startServer(t, e)
+
+	body := getAsync(url + "/wait")
+	<-started
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	// in-flight requests are drained, not canceled
+	if got := <-body; got != "done" {
+		t.Errorf("response = %q, want %q", got, "done")
+	}
+}
@@ -0,0 +1,214 @@
+package zouwu
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// minCookieKeyLength is the minimum length of the keys of SetCookieKeys
+const minCookieKeyLength = 32
+
+// errNoCookieKeys is returned when signed or encrypted cookies are used without keys
+var errNoCookieKeys = errors.New("[zouwu Engine]: cookie keys are not set, see Engine.SetCookieKeys")
+
+// CookieOptions are the attributes of the cookies set by Context.SetCookie.
+type CookieOptions struct {
+	// Path defaults to "/".
+	Path   string
+	Domain string
+	// MaxAge in seconds, zero omits the attribute, a negative value deletes the cookie.
+	MaxAge   int
+	Expires  time.Time
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+	// Partitioned stores the cookie in a storage partitioned by top-level site (CHIPS),
+	// it requires Secure.
+	Partitioned bool
+}
+
+// cookieKey holds the keys derived from one key of SetCookieKeys
+type cookieKey struct {
+	sign []byte
+	aead cipher.AEAD
+}
+
+// SetCookieKeys sets the keys of the signed and encrypted cookies, each at least 32 bytes long.
+// The first key signs and encrypts the new cookies, all the keys verify and decrypt them:
+// to rotate the keys, prepend the new key and drop the oldest once its cookies expired.
+//     engine.SetCookieKeys(newKey, oldKey)
+// It panics if a key is too short.
+func (engine *Engine) SetCookieKeys(keys ...[]byte) {
+	cookieKeys := make([]cookieKey, 0, len(keys))
+	for _, key := range keys {
+		if len(key) < minCookieKeyLength {
+			panic("[zouwu Engine]: cookie keys must be at least 32 bytes long")
+		}
+		block, err := aes.NewCipher(deriveCookieKey(key, "encrypt"))
+		if err != nil {
+			panic(err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(err)
+		}
+		cookieKeys = append(cookieKeys, cookieKey{sign: deriveCookieKey(key, "sign"), aead: aead})
+	}
+	engine.cookieKeys = cookieKeys
+}
+
+// deriveCookieKey derives independent signing and encryption keys from one key
+func deriveCookieKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("zouwu cookie " + purpose))
+	return mac.Sum(nil)
+}
+
+// Cookie returns the value of the request cookie name, or an empty string.
+func (c *Context) Cookie(name string) string {
+	return string(c.Ctx.Request.Header.Cookie(name))
+}
+
+// Cookies returns all the request cookies.
+func (c *Context) Cookies() map[string]string {
+	cookies := make(map[string]string)
+	c.Ctx.Request.Header.VisitAllCookie(func(key, value []byte) {
+		cookies[string(key)] = string(value)
+	})
+	return cookies
+}
+
+// SetCookie adds a Set-Cookie header to the response, it replaces any cookie
+// with the same name set before.
+//     c.SetCookie("theme", "dark", zouwu.CookieOptions{MaxAge: 86400, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+func (c *Context) SetCookie(name, value string, options ...CookieOptions) {
+	var opts CookieOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     opts.Path,
+		Domain:   opts.Domain,
+		MaxAge:   opts.MaxAge,
+		Expires:  opts.Expires,
+		Secure:   opts.Secure || opts.Partitioned,
+		HttpOnly: opts.HttpOnly,
+		SameSite: opts.SameSite,
+	}
+	raw := cookie.String()
+	if raw == "" {
+		// net/http drops the cookies with an invalid name
+		return
+	}
+	if opts.Partitioned {
+		raw += "; Partitioned"
+	}
+	header := &c.Ctx.Response.Header
+	header.DelCookie(name)
+	header.Set(HeaderSetCookie, raw)
+}
+
+// ClearCookie tells the client to delete the cookie name, options must have the Path and Domain
+// the cookie was set with.
+func (c *Context) ClearCookie(name string, options ...CookieOptions) {
+	var opts CookieOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	opts.MaxAge = -1
+	opts.Expires = time.Unix(0, 0)
+	c.SetCookie(name, "", opts)
+}
+
+// SetSignedCookie sets a cookie whose value can be read by the client but not modified,
+// see Engine.SetCookieKeys.
+func (c *Context) SetSignedCookie(name, value string, options ...CookieOptions) error {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return errNoCookieKeys
+	}
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(value))
+	c.SetCookie(name, encoded+"."+cookieSignature(keys[0].sign, name, encoded), options...)
+	return nil
+}
+
+// SignedCookie returns the value of the signed cookie name,
+// ok is false when the cookie is missing or its signature is invalid.
+func (c *Context) SignedCookie(name string) (value string, ok bool) {
+	raw := c.Cookie(name)
+	i := strings.LastIndexByte(raw, '.')
+	if i < 0 {
+		return "", false
+	}
+	encoded, signature := raw[:i], raw[i+1:]
+	for _, key := range c.engine.cookieKeys {
+		if hmac.Equal([]byte(signature), []byte(cookieSignature(key.sign, name, encoded))) {
+			decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+			if err != nil {
+				return "", false
+			}
+			return string(decoded), true
+		}
+	}
+	return "", false
+}
+
+// cookieSignature binds the signature to the cookie name so a value can not be moved to another cookie
+func cookieSignature(key []byte, name, encoded string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'='})
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SetEncryptedCookie sets a cookie whose value can be neither read nor modified by the client,
+// it is encrypted with AES-GCM, see Engine.SetCookieKeys.
+func (c *Context) SetEncryptedCookie(name, value string, options ...CookieOptions) error {
+	keys := c.engine.cookieKeys
+	if len(keys) == 0 {
+		return errNoCookieKeys
+	}
+	aead := keys[0].aead
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.Wrap(err, "[zouwu Engine]: generate cookie nonce")
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	c.SetCookie(name, base64.RawURLEncoding.EncodeToString(sealed), options...)
+	return nil
+}
+
+// EncryptedCookie returns the value of the encrypted cookie name,
+// ok is false when the cookie is missing or can not be decrypted.
+func (c *Context) EncryptedCookie(name string) (value string, ok bool) {
+	sealed, err := base64.RawURLEncoding.DecodeString(c.Cookie(name))
+	if err != nil {
+		return "", false
+	}
+	for _, key := range c.engine.cookieKeys {
+		size := key.aead.NonceSize()
+		if len(sealed) < size {
+			return "", false
+		}
+		if plain, err := key.aead.Open(nil, sealed[:size], sealed[size:], []byte(name)); err == nil {
+			return string(plain), true
+		}
+	}
+	return "", false
+}
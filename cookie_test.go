@@ -0,0 +1,178 @@
+package zouwu_test
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/zouwutest"
+)
+
+var (
+	cookieKey    = bytes.Repeat([]byte("k"), 32)
+	oldCookieKey = bytes.Repeat([]byte("o"), 32)
+)
+
+// setTestCookies sets a plain, a signed and an encrypted cookie
+func setTestCookies(c *zouwu.Context) error {
+	c.SetCookie("plain", "v1")
+	if err := c.SetSignedCookie("signed", "user=bob"); err != nil {
+		return err
+	}
+	if err := c.SetEncryptedCookie("secret", "token"); err != nil {
+		return err
+	}
+	return c.String("set")
+}
+
+// getTestCookies responds the cookies set by setTestCookies, a hyphen for the invalid ones
+func getTestCookies(c *zouwu.Context) error {
+	signed, signedOK := c.SignedCookie("signed")
+	secret, secretOK := c.EncryptedCookie("secret")
+	if !signedOK {
+		signed = "-"
+	}
+	if !secretOK {
+		secret = "-"
+	}
+	return c.String(c.Cookie("plain") + "|" + signed + "|" + secret)
+}
+
+// setCookies return the cookies set by resp, indexed by name
+func setCookies(resp *zouwutest.Response) map[string]*http.Cookie {
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range resp.Raw().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+func TestSignedAndEncryptedCookies(t *testing.T) {
+	e := zouwu.NewServer()
+	e.SetCookieKeys(cookieKey)
+	e.GET("/set", setTestCookies)
+	e.GET("/get", getTestCookies)
+	client := zouwutest.New(t, e)
+	cookies := setCookies(client.GET("/set").Expect().Status(http.StatusOK))
+	if len(cookies) != 3 {
+		t.Fatalf("cookies = %v, want 3 cookies", cookies)
+	}
+	if strings.Contains(cookies["secret"].Value, "token") {
+		t.Errorf("encrypted cookie = %q, want the value hidden", cookies["secret"].Value)
+	}
+
+	get := func(signed, secret string) *zouwutest.Response {
+		return client.GET("/get").
+			WithCookie("plain", cookies["plain"].Value).
+			WithCookie("signed", signed).
+			WithCookie("secret", secret).
+			Expect()
+	}
+	get(cookies["signed"].Value, cookies["secret"].Value).Body("v1|user=bob|token")
+
+	// tampered values are rejected
+	signed := cookies["signed"].Value
+	i := strings.LastIndexByte(signed, '.')
+	forged := "dXNlcj1hZG1pbg" + signed[i:]
+	get(forged, cookies["secret"].Value+"A").Body("v1|-|-")
+	get("nodot", "!").Body("v1|-|-")
+	// values are bound to the name of their cookie
+	client.GET("/get").
+		WithCookie("signed", cookies["secret"].Value).
+		WithCookie("secret", cookies["signed"].Value).
+		Expect().
+		Body("|-|-")
+}
+
+func TestCookieKeyRotation(t *testing.T) {
+	oldEngine := zouwu.NewServer()
+	oldEngine.SetCookieKeys(oldCookieKey)
+	oldEngine.GET("/set", setTestCookies)
+	oldEngine.GET("/get", getTestCookies)
+	newEngine := zouwu.NewServer()
+	newEngine.SetCookieKeys(cookieKey)
+	newEngine.GET("/get", getTestCookies)
+	rotatedEngine := zouwu.NewServer()
+	rotatedEngine.SetCookieKeys(cookieKey, oldCookieKey)
+	rotatedEngine.GET("/set", setTestCookies)
+	rotatedEngine.GET("/get", getTestCookies)
+
+	old := setCookies(zouwutest.New(t, oldEngine).GET("/set").Expect())
+	rotated := zouwutest.New(t, rotatedEngine)
+	rotated.GET("/get").
+		WithCookie("signed", old["signed"].Value).
+		WithCookie("secret", old["secret"].Value).
+		Expect().
+		Body("|user=bob|token")
+	// new cookies are signed with the first key only
+	fresh := setCookies(rotated.GET("/set").Expect())
+	zouwutest.New(t, oldEngine).GET("/get").
+		WithCookie("signed", fresh["signed"].Value).
+		WithCookie("secret", fresh["secret"].Value).
+		Expect().
+		Body("|-|-")
+	zouwutest.New(t, newEngine).GET("/get").
+		WithCookie("signed", fresh["signed"].Value).
+		WithCookie("secret", fresh["secret"].Value).
+		Expect().
+		Body("|user=bob|token")
+}
+
+func TestCookieKeysRequired(t *testing.T) {
+	e := zouwu.NewServer()
+	e.GET("/set", setTestCookies)
+	e.GET("/get", getTestCookies)
+	client := zouwutest.New(t, e)
+	client.GET("/set").Expect().Status(http.StatusInternalServerError)
+	client.GET("/get").WithCookie("signed", "a.b").Expect().Body("|-|-")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic on the short key")
+		}
+	}()
+	zouwu.NewServer().SetCookieKeys(cookieKey, []byte("short"))
+}
+
+func TestSetCookieOptions(t *testing.T) {
+	e := zouwu.NewServer()
+	e.GET("/", func(c *zouwu.Context) error {
+		c.SetCookie("theme", "light")
+		c.SetCookie("theme", "dark", zouwu.CookieOptions{
+			Path:     "/app",
+			Domain:   "example.com",
+			MaxAge:   3600,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		c.SetCookie("embed", "1", zouwu.CookieOptions{SameSite: http.SameSiteNoneMode, Partitioned: true})
+		c.SetCookie("bad name", "1")
+		c.ClearCookie("session", zouwu.CookieOptions{Path: "/app"})
+		return c.String(c.Cookies()["a"] + c.Cookies()["b"])
+	})
+	resp := zouwutest.New(t, e).GET("/").WithCookie("a", "1").WithCookie("b", "2").Expect().Body("12")
+
+	headers := resp.Raw().Header.Values(zouwu.HeaderSetCookie)
+	if len(headers) != 3 {
+		t.Fatalf("Set-Cookie = %q, want 3 cookies", headers)
+	}
+	wants := map[string][]string{
+		"theme":   {"theme=dark", "Path=/app", "Domain=example.com", "Max-Age=3600", "HttpOnly", "SameSite=Lax"},
+		"embed":   {"embed=1", "Path=/", "Secure", "SameSite=None", "Partitioned"},
+		"session": {"session=", "Path=/app", "Max-Age=0", "Expires=Thu, 01 Jan 1970 00:00:00 GMT"},
+	}
+	for _, header := range headers {
+		name := header[:strings.IndexByte(header, '=')]
+		for _, want := range wants[name] {
+			if !strings.Contains(header, want) {
+				t.Errorf("Set-Cookie %q, want it to contain %q", header, want)
+			}
+		}
+		delete(wants, name)
+	}
+	if len(wants) > 0 {
+		t.Errorf("missing cookies %v", wants)
+	}
+}
@@ -4,16 +4,18 @@ import "net/http"
 
 // define error
 var (
-	ErrNotFound            = NewHTTPError(http.StatusNotFound)
-	ErrUnauthorized        = NewHTTPError(http.StatusUnauthorized)
-	ErrForbidden           = NewHTTPError(http.StatusForbidden)
-	ErrMethodNotAllowed    = NewHTTPError(http.StatusMethodNotAllowed)
-	ErrTooManyRequests     = NewHTTPError(http.StatusTooManyRequests)
-	ErrBadRequest          = NewHTTPError(http.StatusBadRequest)
-	ErrBadGateway          = NewHTTPError(http.StatusBadGateway)
-	ErrInternalServerError = NewHTTPError(http.StatusInternalServerError)
-	ErrRequestTimeout      = NewHTTPError(http.StatusRequestTimeout)
-	ErrServiceUnavailable  = NewHTTPError(http.StatusServiceUnavailable)
+	ErrNotFound             = NewHTTPError(http.StatusNotFound)
+	ErrUnauthorized         = NewHTTPError(http.StatusUnauthorized)
+	ErrForbidden            = NewHTTPError(http.StatusForbidden)
+	ErrMethodNotAllowed     = NewHTTPError(http.StatusMethodNotAllowed)
+	ErrTooManyRequests      = NewHTTPError(http.StatusTooManyRequests)
+	ErrBadRequest           = NewHTTPError(http.StatusBadRequest)
+	ErrBadGateway           = NewHTTPError(http.StatusBadGateway)
+	ErrInternalServerError  = NewHTTPError(http.StatusInternalServerError)
+	ErrRequestTimeout       = NewHTTPError(http.StatusRequestTimeout)
+	ErrServiceUnavailable   = NewHTTPError(http.StatusServiceUnavailable)
+	ErrUnsupportedMediaType = NewHTTPError(http.StatusUnsupportedMediaType)
+	ErrNotAcceptable        = NewHTTPError(http.StatusNotAcceptable)
 )
 
 // Error error
@@ -0,0 +1,116 @@
+package zouwu
+
+import (
+	"net"
+	"strings"
+)
+
+// virtualHost holds the routes registered by Engine.Host for one host pattern
+type virtualHost struct {
+	pattern string
+	labels  []string
+	// wildcard is true when a label captures a param
+	wildcard bool
+	trees    methodTrees
+}
+
+// Host returns a router whose routes only serve the requests for host, with its own
+// middleware. A label beginning with ':' captures the request label into Params:
+//     tenants := engine.Host(":tenant.example.com")
+//     tenants.GET("/", func(c *zouwu.Context) error {
+//         return c.String(c.URLParam("tenant"))
+//     })
+// Exact hosts are matched before wildcard ones. Requests matching a host are routed in its
+// routes only, the others in the routes registered on the Engine.
+// The trailing dot of fully qualified hosts is ignored. It panics if a label of host is empty.
+func (engine *Engine) Host(host string, handlers ...HandlerFunc) *RouterGroup {
+	pattern := strings.TrimSuffix(strings.ToLower(host), ".")
+	var vh *virtualHost
+	for _, h := range engine.hosts {
+		if h.pattern == pattern {
+			vh = h
+			break
+		}
+	}
+	if vh == nil {
+		vh = newVirtualHost(pattern)
+		engine.hosts = append(engine.hosts, vh)
+	}
+	return &RouterGroup{
+		Handlers: engine.combineHandlers(handlers),
+		basePath: "/",
+		engine:   engine,
+		root:     false,
+		host:     vh,
+	}
+}
+
+func newVirtualHost(pattern string) *virtualHost {
+	if pattern == "" {
+		panic("[zouwu Engine]: host can not be empty")
+	}
+	vh := &virtualHost{pattern: pattern, labels: strings.Split(pattern, ".")}
+	for _, label := range vh.labels {
+		if label == "" {
+			panic("[zouwu Engine]: empty label in host '" + pattern + "'")
+		}
+		if strings.HasPrefix(label, ":") {
+			if len(label) < 2 {
+				panic("[zouwu Engine]: host params must be named in host '" + pattern + "'")
+			}
+			vh.wildcard = true
+		}
+	}
+	return vh
+}
+
+// match reports whether host matches the pattern, appending the captured labels to params.
+func (vh *virtualHost) match(host string, params Params) (Params, bool) {
+	if !vh.wildcard {
+		return params, host == vh.pattern
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) != len(vh.labels) {
+		return params, false
+	}
+	for i, label := range vh.labels {
+		if labels[i] == "" || label[0] != ':' && label != labels[i] {
+			return params, false
+		}
+	}
+	for i, label := range vh.labels {
+		if label[0] == ':' {
+			params = append(params, Param{Key: label[1:], Value: labels[i]})
+		}
+	}
+	return params, true
+}
+
+// matchHost return the trees serving the request Host and the params captured from it
+func (engine *Engine) matchHost(ctx *Context) (methodTrees, Params) {
+	if len(engine.hosts) == 0 {
+		return engine.trees, nil
+	}
+	host := strings.ToLower(string(ctx.Ctx.Host()))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	} else if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		// IPv6 literal without a port
+		host = host[1 : len(host)-1]
+	}
+	host = strings.TrimSuffix(host, ".")
+	for _, vh := range engine.hosts {
+		if !vh.wildcard && vh.pattern == host {
+			return vh.trees, nil
+		}
+	}
+	for _, vh := range engine.hosts {
+		if !vh.wildcard {
+			continue
+		}
+		if params, ok := vh.match(host, nil); ok {
+			return vh.trees, params
+		}
+	}
+	return engine.trees, nil
+}
@@ -0,0 +1,88 @@
+package zouwu
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHost(t *testing.T) {
+	e := NewServer()
+	e.GET("/", func(c *Context) error { return c.String("default") })
+	e.Host("admin.example.com").GET("/", func(c *Context) error { return c.String("admin") })
+	e.Host("::1").GET("/", func(c *Context) error { return c.String("ipv6") })
+	e.Host(":tenant.example.com.").GET("/", func(c *Context) error {
+		return c.String("tenant " + c.URLParam("tenant"))
+	})
+	e.Host(":env.:region.example.com").GET("/", func(c *Context) error {
+		return c.String(c.URLParam("env") + " " + c.URLParam("region"))
+	})
+
+	tests := []struct {
+		host string
+		body string
+	}{
+		{"example.com", "default"},
+		{"admin.example.com", "admin"},
+		{"ADMIN.example.com:8080", "admin"},
+		{"admin.example.com.", "admin"},
+		{"[::1]", "ipv6"},
+		{"[::1]:8080", "ipv6"},
+		{"acme.example.com", "tenant acme"},
+		{"acme.example.com.", "tenant acme"},
+		{"prod.eu.example.com", "prod eu"},
+		{"prod.eu.example.com.", "prod eu"},
+		{"a.b.c.example.com.", "default"},
+		{".example.com", "default"},
+		{"..example.com", "default"},
+	}
+	for _, tt := range tests {
+		req := newRequest(http.MethodGet, "/")
+		req.Header.SetHost(tt.host)
+		resp := performRequest(e, req)
+		if body := string(resp.Body()); resp.StatusCode() != http.StatusOK || body != tt.body {
+			t.Errorf("Host %q = %d %q, want 200 %q", tt.host, resp.StatusCode(), body, tt.body)
+		}
+	}
+}
+
+func TestHostEmptyLabel(t *testing.T) {
+	for _, host := range []string{"", ".", "a..example.com", ":tenant..com", ".example.com"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Host(%q) did not panic", host)
+				}
+			}()
+			NewServer().Host(host)
+		}()
+	}
+}
+
+func TestHostMethodConfig(t *testing.T) {
+	e := NewServer()
+	e.GET("/slow", ok)
+	e.Host("admin.example.com").SetMethodConfig(&MethodConfig{Timeout: time.Second}).GET("/slow", ok)
+	e.POST("/slow", ok)
+	e.SetMethodConfig("/fast", &MethodConfig{Timeout: time.Millisecond})
+	e.GET("/fast", ok)
+	e.PUT("/fast", ok)
+
+	want := map[string]time.Duration{
+		"GET /slow":                  0,
+		"GET admin.example.com/slow": time.Second,
+		"POST /slow":                 0,
+		"GET /fast":                  time.Millisecond,
+		"PUT /fast":                  time.Millisecond,
+	}
+	for _, route := range e.Routes() {
+		key := route.Method + " " + route.Host + route.Path
+		var got time.Duration
+		if route.Config != nil {
+			got = route.Config.Timeout
+		}
+		if got != want[key] {
+			t.Errorf("timeout of %s = %v, want %v", key, got, want[key])
+		}
+	}
+}
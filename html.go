@@ -0,0 +1,220 @@
+package zouwu
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// HTMLTemplate is rendered with the template Name when Context.Render negotiates text/html,
+// the other renderers encode Data alone.
+//     return c.Render(http.StatusOK, zouwu.HTMLTemplate{Name: "user.html", Data: user})
+type HTMLTemplate struct {
+	Name string
+	Data interface{}
+}
+
+// MarshalJSON encodes Data
+func (t HTMLTemplate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Data)
+}
+
+// MarshalXML encodes Data
+func (t HTMLTemplate) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(t.Data)
+}
+
+// String formats Data
+func (t HTMLTemplate) String() string {
+	return fmt.Sprint(t.Data)
+}
+
+// htmlRender holds the templates loaded by Engine.LoadHTMLGlob and Engine.LoadHTMLFS,
+// it is the Renderer of text/html.
+type htmlRender struct {
+	engine *Engine
+
+	mu   sync.RWMutex
+	conf htmlConfig
+	set  *htmlSet
+}
+
+// htmlConfig is what the templates are built from
+type htmlConfig struct {
+	funcMap template.FuncMap
+	layout  string
+	parse   func(t *template.Template) (*template.Template, error)
+}
+
+// htmlSet is one parsed generation of templates
+type htmlSet struct {
+	root   *template.Template
+	layout string
+	// pages holds one clone of root per template, with yield bound to that template
+	pages map[string]*template.Template
+}
+
+// LoadHTMLGlob parses the templates matched by pattern, templates are named by their file name.
+// The templates already loaded are kept when parsing fails.
+func (engine *Engine) LoadHTMLGlob(pattern string) error {
+	return engine.html.update(func(conf *htmlConfig) {
+		conf.parse = func(t *template.Template) (*template.Template, error) {
+			return t.ParseGlob(pattern)
+		}
+	})
+}
+
+// LoadHTMLFS parses the templates of fsys matched by patterns, e.g. an embed.FS.
+// The templates already loaded are kept when parsing fails.
+func (engine *Engine) LoadHTMLFS(fsys fs.FS, patterns ...string) error {
+	return engine.html.update(func(conf *htmlConfig) {
+		conf.parse = func(t *template.Template) (*template.Template, error) {
+			return t.ParseFS(fsys, patterns...)
+		}
+	})
+}
+
+// SetFuncMap sets the FuncMap available in the HTML templates, the loaded templates are parsed again.
+func (engine *Engine) SetFuncMap(funcMap template.FuncMap) error {
+	return engine.html.update(func(conf *htmlConfig) {
+		conf.funcMap = funcMap
+	})
+}
+
+// SetHTMLLayout sets the template every page is rendered in by Context.HTML.
+// The layout inserts the page with the yield function:
+//     <body>{{ yield . }}</body>
+func (engine *Engine) SetHTMLLayout(name string) error {
+	return engine.html.update(func(conf *htmlConfig) {
+		conf.layout = name
+	})
+}
+
+// update applies change to the config and parses the templates again,
+// nothing is changed when the templates do not build.
+func (h *htmlRender) update(change func(conf *htmlConfig)) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conf := h.conf
+	change(&conf)
+	if conf.parse == nil {
+		h.conf = conf
+		return nil
+	}
+	set, err := conf.build()
+	if err != nil {
+		return err
+	}
+	h.conf, h.set = conf, set
+	return nil
+}
+
+// templates return the current templates, they are parsed again on every call in debug mode
+// and replace the current ones when they build.
+func (h *htmlRender) templates() (*htmlSet, error) {
+	if !h.engine.DebugMode {
+		h.mu.RLock()
+		set := h.set
+		h.mu.RUnlock()
+		if set == nil {
+			return nil, errors.New("[zouwu Engine]: HTML templates are not loaded")
+		}
+		return set, nil
+	}
+
+	h.mu.RLock()
+	conf := h.conf
+	h.mu.RUnlock()
+	if conf.parse == nil {
+		return nil, errors.New("[zouwu Engine]: HTML templates are not loaded")
+	}
+	set, err := conf.build()
+	if err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	h.set = set
+	h.mu.Unlock()
+	return set, nil
+}
+
+func (h *htmlRender) ContentType() string {
+	return MIMETextHTMLCharsetUTF8
+}
+
+// Render renders HTMLTemplate with the loaded templates, other data is escaped like HTMLRenderer.
+func (h *htmlRender) Render(data interface{}) ([]byte, error) {
+	t, ok := data.(HTMLTemplate)
+	if !ok {
+		return HTMLRenderer.Render(data)
+	}
+	set, err := h.templates()
+	if err != nil {
+		return nil, err
+	}
+	return set.execute(t.Name, t.Data)
+}
+
+func (conf htmlConfig) build() (*htmlSet, error) {
+	root := template.New("").Funcs(template.FuncMap{
+		"yield": func(interface{}) (template.HTML, error) {
+			return "", errors.New("yield called outside of a layout")
+		},
+	}).Funcs(conf.funcMap)
+	root, err := conf.parse(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "[zouwu Engine]: parse HTML templates")
+	}
+	set := &htmlSet{root: root, layout: conf.layout}
+	if conf.layout == "" {
+		return set, nil
+	}
+	if root.Lookup(conf.layout) == nil {
+		return nil, errors.Errorf("[zouwu Engine]: HTML layout %q is not defined", conf.layout)
+	}
+
+	set.pages = make(map[string]*template.Template)
+	for _, t := range root.Templates() {
+		name := t.Name()
+		if name == "" || name == conf.layout {
+			continue
+		}
+		page, err := root.Clone()
+		if err != nil {
+			return nil, err
+		}
+		page.Funcs(template.FuncMap{
+			"yield": func(data interface{}) (template.HTML, error) {
+				var buf bytes.Buffer
+				err := page.ExecuteTemplate(&buf, name, data)
+				return template.HTML(buf.String()), err
+			},
+		})
+		set.pages[name] = page
+	}
+	return set, nil
+}
+
+// execute renders the template name, within the layout if one is set.
+func (set *htmlSet) execute(name string, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if set.layout == "" {
+		if err := set.root.ExecuteTemplate(&buf, name, data); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	page, ok := set.pages[name]
+	if !ok {
+		return nil, errors.Errorf("[zouwu Engine]: HTML template %q is not defined", name)
+	}
+	if err := page.ExecuteTemplate(&buf, set.layout, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
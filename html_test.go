@@ -0,0 +1,135 @@
+package zouwu
+
+import (
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeTemplate(t *testing.T, dir, name, text string) {
+	t.Helper()
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// htmlEngine return an engine rendering the template named by the path with data
+func htmlEngine(data interface{}) *Engine {
+	e := NewServer()
+	e.GET("/:name", func(c *Context) error {
+		name, _ := c.Params.Get("name")
+		return c.HTML(http.StatusOK, name, data)
+	})
+	return e
+}
+
+func TestHTML(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/layout.html": {Data: []byte(`<main>{{ yield . }}</main>`)},
+		"views/user.html":   {Data: []byte(`<p>{{ upper .Name }}</p><a href="/u?n={{ .Name }}">{{ .Bio }}</a>`)},
+	}
+	data := map[string]string{"Name": "a&b", "Bio": "<script>"}
+	e := htmlEngine(data)
+	if err := e.SetFuncMap(template.FuncMap{"upper": strings.ToUpper}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.LoadHTMLFS(fsys, "views/*.html"); err != nil {
+		t.Fatal(err)
+	}
+	want := `<p>A&amp;B</p><a href="/u?n=a%26b">&lt;script&gt;</a>`
+	resp := performRequest(e, newRequest(http.MethodGet, "/user.html"))
+	if resp.StatusCode() != http.StatusOK || string(resp.Body()) != want {
+		t.Errorf("HTML() = %d %q, want 200 %q", resp.StatusCode(), resp.Body(), want)
+	}
+
+	if err := e.SetHTMLLayout("layout.html"); err != nil {
+		t.Fatal(err)
+	}
+	resp = performRequest(e, newRequest(http.MethodGet, "/user.html"))
+	if body := string(resp.Body()); body != "<main>"+want+"</main>" {
+		t.Errorf("HTML() in layout = %q, want %q", body, "<main>"+want+"</main>")
+	}
+	if err := e.SetHTMLLayout("missing.html"); err == nil {
+		t.Error("SetHTMLLayout() with an undefined layout = nil, want an error")
+	}
+}
+
+func TestHTMLReload(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "index.html", `v1`)
+	e := NewServer()
+	if err := e.LoadHTMLGlob(filepath.Join(dir, "*.html")); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/", func(c *Context) error {
+		return c.HTML(http.StatusOK, "index.html", nil)
+	})
+	get := func() string {
+		resp := performRequest(e, newRequest(http.MethodGet, "/"))
+		return string(resp.Body())
+	}
+
+	// templates are parsed once outside of debug mode
+	writeTemplate(t, dir, "index.html", `v2`)
+	if got := get(); got != "v1" {
+		t.Errorf("body = %q, want %q", got, "v1")
+	}
+	e.SetDebugMode()
+	if got := get(); got != "v2" {
+		t.Errorf("debug body = %q, want %q", got, "v2")
+	}
+
+	// a parse error is rendered and the last good templates are kept
+	writeTemplate(t, dir, "index.html", `{{ .Broken `)
+	resp := performRequest(e, newRequest(http.MethodGet, "/"))
+	if resp.StatusCode() != http.StatusInternalServerError || !strings.Contains(string(resp.Body()), "parse HTML templates") {
+		t.Errorf("debug parse error = %d %q, want 500 with the parse error", resp.StatusCode(), resp.Body())
+	}
+	e.DebugMode = false
+	if got := get(); got != "v2" {
+		t.Errorf("body after a parse error = %q, want %q", got, "v2")
+	}
+	if err := e.LoadHTMLGlob(filepath.Join(dir, "*.html")); err == nil {
+		t.Error("LoadHTMLGlob() of a broken template = nil, want an error")
+	}
+	if got := get(); got != "v2" {
+		t.Errorf("body after a failed load = %q, want %q", got, "v2")
+	}
+}
+
+func TestHTMLNotLoaded(t *testing.T) {
+	resp := performRequest(htmlEngine(nil), newRequest(http.MethodGet, "/index.html"))
+	if resp.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", resp.StatusCode(), http.StatusInternalServerError)
+	}
+}
+
+func TestRenderHTMLTemplate(t *testing.T) {
+	e := NewServer()
+	if err := e.LoadHTMLFS(fstest.MapFS{"user.html": {Data: []byte(`<p>{{ .Name }}</p>`)}}, "*.html"); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/", func(c *Context) error {
+		return c.Render(http.StatusOK, HTMLTemplate{Name: "user.html", Data: renderUser{"<b>"}})
+	})
+	tests := []struct {
+		accept string
+		body   string
+	}{
+		{"text/html", `<p>&lt;b&gt;</p>`},
+		{"application/json", `{"name":"\u003cb\u003e"}`},
+		{"application/xml", `<renderUser><name>&lt;b&gt;</name></renderUser>`},
+		{"text/plain", `user <b>`},
+	}
+	for _, tt := range tests {
+		req := newRequest(http.MethodGet, "/")
+		req.Header.Set(HeaderAccept, tt.accept)
+		if body := string(performRequest(e, req).Body()); body != tt.body {
+			t.Errorf("Accept %q: body = %q, want %q", tt.accept, body, tt.body)
+		}
+	}
+}
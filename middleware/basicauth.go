@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/DCRcoder/zouwu"
+)
+
+// BasicAuthUserKey is the key of Context.Keys holding the user authenticated by BasicAuth.
+const BasicAuthUserKey = "zouwu.basicauth.user"
+
+// BasicAuthConfig defines the config of the BasicAuth middleware.
+type BasicAuthConfig struct {
+	// Users maps the user names to their password.
+	Users map[string]string
+	// Validator checks the credentials not found in Users, it should compare them in constant time.
+	Validator func(ctx *zouwu.Context, user, password string) bool
+	// Realm is sent in the WWW-Authenticate header, defaults to "Restricted".
+	Realm string
+	// ContextKey is the key of the user name in Context.Keys, defaults to BasicAuthUserKey.
+	ContextKey string
+}
+
+// basicCredential is the digest of one user of BasicAuthConfig.Users
+type basicCredential struct {
+	user     string
+	userHash [sha256.Size]byte
+	passHash [sha256.Size]byte
+}
+
+// BasicAuth returns a middleware for HTTP Basic authentication, the requests without valid
+// credentials are answered with zouwu.ErrUnauthorized and a WWW-Authenticate header.
+//     admin := router.Group("/admin", middleware.BasicAuth(middleware.BasicAuthConfig{
+//         Users: map[string]string{"admin": "secret"},
+//     }))
+func BasicAuth(config BasicAuthConfig) zouwu.HandlerFunc {
+	conf := config
+	if len(conf.Users) == 0 && conf.Validator == nil {
+		panic("[zouwu BasicAuth]: Users or Validator is required")
+	}
+	if conf.Realm == "" {
+		conf.Realm = "Restricted"
+	}
+	if conf.ContextKey == "" {
+		conf.ContextKey = BasicAuthUserKey
+	}
+	credentials := make([]basicCredential, 0, len(conf.Users))
+	for user, password := range conf.Users {
+		credentials = append(credentials, basicCredential{
+			user:     user,
+			userHash: sha256.Sum256([]byte(user)),
+			passHash: sha256.Sum256([]byte(password)),
+		})
+	}
+	challenge := "Basic realm=" + strconv.Quote(conf.Realm) + `, charset="UTF-8"`
+
+	return func(ctx *zouwu.Context) error {
+		user, password, ok := parseBasicAuth(string(ctx.Ctx.Request.Header.Peek(zouwu.HeaderAuthorization)))
+		if ok && basicAuthenticate(ctx, &conf, credentials, user, password) {
+			ctx.Set(conf.ContextKey, user)
+			return nil
+		}
+		ctx.Ctx.Response.Header.Set(zouwu.HeaderWWWAuthenticate, challenge)
+		return zouwu.ErrUnauthorized
+	}
+}
+
+// basicAuthenticate compares the credentials with every user so the time taken
+// does not tell whether the user exists
+func basicAuthenticate(ctx *zouwu.Context, conf *BasicAuthConfig, credentials []basicCredential, user, password string) bool {
+	userHash := sha256.Sum256([]byte(user))
+	passHash := sha256.Sum256([]byte(password))
+	match := 0
+	for i := range credentials {
+		c := &credentials[i]
+		match |= subtle.ConstantTimeCompare(userHash[:], c.userHash[:]) & subtle.ConstantTimeCompare(passHash[:], c.passHash[:])
+	}
+	if match == 1 {
+		return true
+	}
+	return conf.Validator != nil && conf.Validator(ctx, user, password)
+}
+
+// parseBasicAuth parses the "Basic base64(user:password)" Authorization header
+func parseBasicAuth(header string) (user, password string, ok bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(header[len(prefix):]))
+	if err != nil {
+		return "", "", false
+	}
+	credentials := string(decoded)
+	i := strings.IndexByte(credentials, ':')
+	if i < 0 {
+		return "", "", false
+	}
+	return credentials[:i], credentials[i+1:], true
+}
@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/middleware"
+)
+
+func basicAuthHeader(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+func TestBasicAuth(t *testing.T) {
+	client := newClient(t, middleware.BasicAuth(middleware.BasicAuthConfig{
+		Users: map[string]string{"admin": "secret", "ops": "p:ss"},
+		Validator: func(ctx *zouwu.Context, user, password string) bool {
+			return user == "ldap" && subtle.ConstantTimeCompare([]byte(password), []byte("directory")) == 1
+		},
+		Realm: "Admin",
+	}), map[string]zouwu.HandlerFunc{
+		"GET /admin/": func(c *zouwu.Context) error {
+			return c.String(c.MustGet(middleware.BasicAuthUserKey).(string))
+		},
+	})
+
+	client.GET("/admin/").WithHeader(zouwu.HeaderAuthorization, basicAuthHeader("admin", "secret")).Expect().
+		Status(http.StatusOK).
+		NoHeader(zouwu.HeaderWWWAuthenticate).
+		Body("admin")
+	// the password may contain a colon
+	client.GET("/admin/").WithHeader(zouwu.HeaderAuthorization, basicAuthHeader("ops", "p:ss")).Expect().
+		Status(http.StatusOK).
+		Body("ops")
+	client.GET("/admin/").WithHeader(zouwu.HeaderAuthorization, basicAuthHeader("ldap", "directory")).Expect().
+		Status(http.StatusOK).
+		Body("ldap")
+
+	for _, header := range []string{
+		"",
+		basicAuthHeader("admin", "wrong"),
+		basicAuthHeader("nobody", "secret"),
+		basicAuthHeader("ldap", "wrong"),
+		"Basic not-base64!",
+		"Basic " + base64.StdEncoding.EncodeToString([]byte("no colon")),
+		"Bearer token",
+	} {
+		req := client.GET("/admin/")
+		if header != "" {
+			req.WithHeader(zouwu.HeaderAuthorization, header)
+		}
+		req.Expect().
+			Status(http.StatusUnauthorized).
+			Header(zouwu.HeaderWWWAuthenticate, `Basic realm="Admin", charset="UTF-8"`)
+	}
+}
+
+func TestBasicAuthContextKey(t *testing.T) {
+	client := newClient(t, middleware.BasicAuth(middleware.BasicAuthConfig{
+		Users:      map[string]string{"admin": "secret"},
+		ContextKey: "user",
+	}), map[string]zouwu.HandlerFunc{
+		"GET /": func(c *zouwu.Context) error { return c.String(c.MustGet("user").(string)) },
+	})
+
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, basicAuthHeader("admin", "secret")).Expect().
+		Status(http.StatusOK).
+		Body("admin")
+	client.GET("/").Expect().
+		Status(http.StatusUnauthorized).
+		Header(zouwu.HeaderWWWAuthenticate, `Basic realm="Restricted", charset="UTF-8"`)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic without Users and Validator")
+		}
+	}()
+	middleware.BasicAuth(middleware.BasicAuthConfig{})
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/valyala/fasthttp"
+)
+
+// CompressLevel trades compression speed for size, it is mapped to the levels of each encoding.
+type CompressLevel int
+
+// Compression levels
+const (
+	CompressLevelDefault CompressLevel = iota
+	CompressLevelBestSpeed
+	CompressLevelBestCompression
+)
+
+// defaultCompressTypes are the compressible media types, a trailing '/' matches all the subtypes
+var defaultCompressTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/x-javascript",
+	"application/xhtml+xml",
+	"application/ld+json",
+	"application/manifest+json",
+	"image/svg+xml",
+}
+
+// CompressConfig defines the config of the Compress middleware.
+type CompressConfig struct {
+	// Level defaults to CompressLevelDefault.
+	Level CompressLevel
+	// MinLength is the minimum body size compressed, defaults to 1024 bytes.
+	MinLength int
+	// ContentTypes lists the compressible media types, a trailing '/' matches all the subtypes
+	// e.g. "text/". Media types with a +json or +xml suffix are always compressible.
+	// Defaults to text, JSON, JavaScript, XML and SVG.
+	ContentTypes []string
+	// Encodings lists the supported encodings in the order of preference, used when the client
+	// accepts several equally. Defaults to br, gzip, deflate.
+	Encodings []string
+}
+
+// Compress returns a middleware compressing the response body with the encoding negotiated
+// from Accept-Encoding. Streamed bodies, already encoded responses, partial responses and
+// bodies shorter than MinLength are sent as is.
+//     api := router.Group("/api")
+//     api.Use(middleware.Compress(middleware.CompressConfig{MinLength: 512}))
+func Compress(config ...CompressConfig) zouwu.HandlerFunc {
+	var conf CompressConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.MinLength <= 0 {
+		conf.MinLength = 1024
+	}
+	if len(conf.ContentTypes) == 0 {
+		conf.ContentTypes = defaultCompressTypes
+	}
+	if len(conf.Encodings) == 0 {
+		conf.Encodings = []string{"br", "gzip", "deflate"}
+	}
+	for _, encoding := range conf.Encodings {
+		if compressorLevel(encoding, conf.Level) < 0 {
+			panic("[zouwu Compress]: unsupported encoding '" + encoding + "'")
+		}
+	}
+
+	return func(ctx *zouwu.Context) error {
+		ctx.Next()
+
+		resp := &ctx.Ctx.Response
+		if !compressible(ctx, conf.ContentTypes) {
+			return nil
+		}
+		resp.Header.Add(zouwu.HeaderVary, zouwu.HeaderAcceptEncoding)
+		body := resp.Body()
+		if len(body) < conf.MinLength {
+			return nil
+		}
+		encoding := ctx.NegotiateEncoding(conf.Encodings...)
+		if encoding == "" {
+			return nil
+		}
+
+		level := compressorLevel(encoding, conf.Level)
+		var compressed []byte
+		switch encoding {
+		case "br":
+			compressed = fasthttp.AppendBrotliBytesLevel(nil, body, level)
+		case "gzip":
+			compressed = fasthttp.AppendGzipBytesLevel(nil, body, level)
+		case "deflate":
+			compressed = fasthttp.AppendDeflateBytesLevel(nil, body, level)
+		}
+		resp.SetBodyRaw(compressed)
+		resp.Header.Set(zouwu.HeaderContentEncoding, encoding)
+		// the compressed representation is no longer byte-identical
+		if etag := resp.Header.Peek(zouwu.HeaderETag); len(etag) > 0 && etag[0] == '"' {
+			resp.Header.Set(zouwu.HeaderETag, "W/"+string(etag))
+		}
+		return nil
+	}
+}
+
+// compressible reports whether the response may be compressed
+func compressible(ctx *zouwu.Context, types []string) bool {
+	resp := &ctx.Ctx.Response
+	status := resp.StatusCode()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusPartialContent ||
+		status == http.StatusNotModified || ctx.Ctx.IsHead() {
+		return false
+	}
+	if resp.IsBodyStream() || len(resp.Header.Peek(zouwu.HeaderContentEncoding)) > 0 {
+		return false
+	}
+	mediaType := strings.ToLower(string(resp.Header.ContentType()))
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	mediaType = strings.TrimSpace(mediaType)
+	if strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	for _, t := range types {
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressorLevel return the level of encoding, or -1 when the encoding is not supported
+func compressorLevel(encoding string, level CompressLevel) int {
+	switch encoding {
+	case "br":
+		switch level {
+		case CompressLevelBestSpeed:
+			return fasthttp.CompressBrotliBestSpeed
+		case CompressLevelBestCompression:
+			return fasthttp.CompressBrotliBestCompression
+		}
+		return fasthttp.CompressBrotliDefaultCompression
+	case "gzip", "deflate":
+		switch level {
+		case CompressLevelBestSpeed:
+			return fasthttp.CompressBestSpeed
+		case CompressLevelBestCompression:
+			return fasthttp.CompressBestCompression
+		}
+		return fasthttp.CompressDefaultCompression
+	}
+	return -1
+}
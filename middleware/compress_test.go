@@ -0,0 +1,143 @@
+package middleware_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/middleware"
+	"github.com/valyala/fasthttp"
+)
+
+var compressBody = strings.Repeat("zouwu compresses this body. ", 64)
+
+var compressRoutes = map[string]zouwu.HandlerFunc{
+	"GET /text":  text(compressBody),
+	"GET /short": text("short"),
+	"GET /png": func(c *zouwu.Context) error {
+		c.Bytes(http.StatusOK, "image/png", []byte(compressBody))
+		return nil
+	},
+	"GET /problem": func(c *zouwu.Context) error {
+		c.Bytes(http.StatusOK, "application/problem+json", []byte(compressBody))
+		return nil
+	},
+	"GET /etag": func(c *zouwu.Context) error {
+		c.Ctx.Response.Header.Set(zouwu.HeaderETag, `"v1"`)
+		return c.String(compressBody)
+	},
+	"GET /encoded": func(c *zouwu.Context) error {
+		c.Ctx.Response.Header.Set(zouwu.HeaderContentEncoding, "identity")
+		return c.String(compressBody)
+	},
+}
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var plain []byte
+	var err error
+	switch encoding {
+	case "br":
+		plain, err = fasthttp.AppendUnbrotliBytes(nil, body)
+	case "gzip":
+		plain, err = fasthttp.AppendGunzipBytes(nil, body)
+	case "deflate":
+		plain, err = fasthttp.AppendInflateBytes(nil, body)
+	default:
+		return string(body)
+	}
+	if err != nil {
+		t.Fatalf("decompress %s body: %v", encoding, err)
+	}
+	return string(plain)
+}
+
+func TestCompressNegotiation(t *testing.T) {
+	client := newClient(t, middleware.Compress(middleware.CompressConfig{}), compressRoutes)
+
+	tests := []struct {
+		accept   string
+		encoding string
+	}{
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"*", "br"},
+		{"gzip;q=1, br;q=0.5", "gzip"},
+		{"br;q=0, *", "gzip"},
+		{"identity", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		resp := client.GET("/text").WithHeader(zouwu.HeaderAcceptEncoding, tt.accept).Expect().
+			Status(http.StatusOK).
+			Header(zouwu.HeaderVary, zouwu.HeaderAcceptEncoding)
+		if tt.encoding == "" {
+			resp.NoHeader(zouwu.HeaderContentEncoding).Body(compressBody)
+			continue
+		}
+		resp.Header(zouwu.HeaderContentEncoding, tt.encoding)
+		if len(resp.Bytes()) >= len(compressBody) {
+			t.Errorf("%q: body of %d bytes is not compressed", tt.accept, len(resp.Bytes()))
+		}
+		if got := decompress(t, tt.encoding, resp.Bytes()); got != compressBody {
+			t.Errorf("%q: decompressed body = %q", tt.accept, got)
+		}
+	}
+}
+
+func TestCompressSkipsResponses(t *testing.T) {
+	client := newClient(t, middleware.Compress(middleware.CompressConfig{}), compressRoutes)
+
+	client.GET("/short").WithHeader(zouwu.HeaderAcceptEncoding, "gzip").Expect().
+		Header(zouwu.HeaderVary, zouwu.HeaderAcceptEncoding).
+		NoHeader(zouwu.HeaderContentEncoding).
+		Body("short")
+	client.GET("/png").WithHeader(zouwu.HeaderAcceptEncoding, "gzip").Expect().
+		NoHeader(zouwu.HeaderVary).
+		NoHeader(zouwu.HeaderContentEncoding).
+		Body(compressBody)
+	client.GET("/encoded").WithHeader(zouwu.HeaderAcceptEncoding, "gzip").Expect().
+		Header(zouwu.HeaderContentEncoding, "identity").
+		Body(compressBody)
+	client.HEAD("/text").WithHeader(zouwu.HeaderAcceptEncoding, "gzip").Expect().
+		NoHeader(zouwu.HeaderContentEncoding)
+	client.GET("/missing").WithHeader(zouwu.HeaderAcceptEncoding, "gzip").Expect().
+		Status(http.StatusNotFound)
+}
+
+func TestCompressConfig(t *testing.T) {
+	client := newClient(t, middleware.Compress(middleware.CompressConfig{
+		Level:        middleware.CompressLevelBestSpeed,
+		MinLength:    4,
+		ContentTypes: []string{"image/png"},
+		Encodings:    []string{"gzip"},
+	}), compressRoutes)
+
+	resp := client.GET("/short").WithHeader(zouwu.HeaderAcceptEncoding, "br, gzip").Expect()
+	// text/plain is no longer in ContentTypes
+	resp.NoHeader(zouwu.HeaderContentEncoding).Body("short")
+	resp = client.GET("/png").WithHeader(zouwu.HeaderAcceptEncoding, "br, gzip").Expect().
+		Header(zouwu.HeaderContentEncoding, "gzip")
+	if got := decompress(t, "gzip", resp.Bytes()); got != compressBody {
+		t.Errorf("decompressed body = %q", got)
+	}
+	// +json types are always compressible
+	client.GET("/problem").WithHeader(zouwu.HeaderAcceptEncoding, "gzip").Expect().
+		Header(zouwu.HeaderContentEncoding, "gzip")
+	client.GET("/png").WithHeader(zouwu.HeaderAcceptEncoding, "br").Expect().
+		NoHeader(zouwu.HeaderContentEncoding)
+
+	client = newClient(t, middleware.Compress(middleware.CompressConfig{}), compressRoutes)
+	client.GET("/etag").WithHeader(zouwu.HeaderAcceptEncoding, "gzip").Expect().
+		Header(zouwu.HeaderETag, `W/"v1"`)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic on the unsupported encoding")
+		}
+	}()
+	middleware.Compress(middleware.CompressConfig{Encodings: []string{"zstd"}})
+}
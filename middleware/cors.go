@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+)
+
+// defaultCORSMethods are allowed by preflight requests to paths without routes
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// CORSConfig defines the config of the CORS middleware.
+type CORSConfig struct {
+	// AllowOrigins lists the allowed origins: "*" for all of them, an exact origin
+	// like "https://example.com" or a subdomain wildcard like "https://*.example.com".
+	// Defaults to "*" when AllowOriginFunc is nil.
+	AllowOrigins []string
+	// AllowOriginFunc allows the origins it returns true for, in addition to AllowOrigins.
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods lists the methods allowed by preflight requests, defaults to the methods
+	// the router has a route for, the Allow header of the router's OPTIONS response.
+	AllowMethods []string
+	// AllowHeaders lists the request headers allowed by preflight requests, defaults to
+	// the headers of Access-Control-Request-Headers.
+	AllowHeaders []string
+	// AllowCredentials allows cookies and authorization headers, it can not be used
+	// with the "*" origin.
+	AllowCredentials bool
+	// ExposeHeaders lists the response headers readable by the client.
+	ExposeHeaders []string
+	// MaxAge sets how long the preflight response may be cached, zero omits the header.
+	MaxAge time.Duration
+}
+
+// corsOrigins is the compiled AllowOrigins
+type corsOrigins struct {
+	all       bool
+	exact     map[string]struct{}
+	wildcards [][2]string
+	fn        func(origin string) bool
+}
+
+func newCORSOrigins(origins []string, fn func(origin string) bool) *corsOrigins {
+	o := &corsOrigins{exact: make(map[string]struct{}), fn: fn}
+	if len(origins) == 0 && fn == nil {
+		o.all = true
+	}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch i := strings.Index(origin, "*"); {
+		case origin == "*":
+			o.all = true
+		case i >= 0:
+			prefix, suffix := origin[:i], origin[i+1:]
+			if strings.Contains(suffix, "*") || !strings.HasPrefix(suffix, ".") {
+				panic("[zouwu CORS]: invalid wildcard origin '" + origin + "'")
+			}
+			o.wildcards = append(o.wildcards, [2]string{prefix, suffix})
+		default:
+			o.exact[origin] = struct{}{}
+		}
+	}
+	return o
+}
+
+func (o *corsOrigins) allowed(origin string) bool {
+	if o.all {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	if _, ok := o.exact[lower]; ok {
+		return true
+	}
+	for _, w := range o.wildcards {
+		if len(lower) > len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+			return true
+		}
+	}
+	return o.fn != nil && o.fn(origin)
+}
+
+// CORS returns a middleware implementing Cross-Origin Resource Sharing.
+// Preflight requests are answered with 204 and the chain is aborted, use it with Engine.Use
+// so it also runs for the OPTIONS requests answered by the router:
+//     router.Use(middleware.CORS(middleware.CORSConfig{
+//         AllowOrigins:     []string{"https://example.com", "https://*.example.com"},
+//         AllowCredentials: true,
+//         MaxAge:           time.Hour,
+//     }))
+func CORS(config ...CORSConfig) zouwu.HandlerFunc {
+	var conf CORSConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	origins := newCORSOrigins(conf.AllowOrigins, conf.AllowOriginFunc)
+	if origins.all && conf.AllowCredentials {
+		panic("[zouwu CORS]: AllowCredentials can not be used with the '*' origin")
+	}
+	allowMethods := strings.Join(conf.AllowMethods, ", ")
+	allowHeaders := strings.Join(conf.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(conf.ExposeHeaders, ", ")
+	maxAge := ""
+	if conf.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(conf.MaxAge.Seconds()))
+	}
+
+	return func(ctx *zouwu.Context) error {
+		header := &ctx.Ctx.Response.Header
+		reqHeader := &ctx.Ctx.Request.Header
+		origin := string(reqHeader.Peek(zouwu.HeaderOrigin))
+		preflight := ctx.Ctx.IsOptions() && len(reqHeader.Peek(zouwu.HeaderAccessControlRequestMethod)) > 0
+		if !origins.all {
+			header.Add(zouwu.HeaderVary, zouwu.HeaderOrigin)
+		}
+		if origin == "" {
+			ctx.Next()
+			return nil
+		}
+		if !origins.allowed(origin) {
+			if preflight {
+				ctx.Status(http.StatusNoContent)
+				ctx.Abort()
+				return nil
+			}
+			ctx.Next()
+			return nil
+		}
+
+		if origins.all {
+			header.Set(zouwu.HeaderAccessControlAllowOrigin, "*")
+		} else {
+			header.Set(zouwu.HeaderAccessControlAllowOrigin, origin)
+		}
+		if conf.AllowCredentials {
+			header.Set(zouwu.HeaderAccessControlAllowCredentials, "true")
+		}
+		if !preflight {
+			if exposeHeaders != "" {
+				header.Set(zouwu.HeaderAccessControlExposeHeaders, exposeHeaders)
+			}
+			ctx.Next()
+			return nil
+		}
+
+		header.Add(zouwu.HeaderVary, zouwu.HeaderAccessControlRequestMethod)
+		header.Add(zouwu.HeaderVary, zouwu.HeaderAccessControlRequestHeaders)
+		methods := allowMethods
+		if methods == "" {
+			methods = string(header.Peek(zouwu.HeaderAllow))
+		}
+		if methods == "" {
+			methods = strings.Join(defaultCORSMethods, ", ")
+		}
+		header.Set(zouwu.HeaderAccessControlAllowMethods, methods)
+		headers := allowHeaders
+		if headers == "" {
+			headers = string(reqHeader.Peek(zouwu.HeaderAccessControlRequestHeaders))
+		}
+		if headers != "" {
+			header.Set(zouwu.HeaderAccessControlAllowHeaders, headers)
+		}
+		if maxAge != "" {
+			header.Set(zouwu.HeaderAccessControlMaxAge, maxAge)
+		}
+		ctx.Status(http.StatusNoContent)
+		ctx.Abort()
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package middleware_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/middleware"
+)
+
+var corsRoutes = map[string]zouwu.HandlerFunc{
+	"GET /items":  text("items"),
+	"POST /items": text("created"),
+}
+
+func TestCORSAllOrigins(t *testing.T) {
+	client := newClient(t, middleware.CORS(middleware.CORSConfig{ExposeHeaders: []string{"X-Total"}}), corsRoutes)
+
+	client.GET("/items").WithHeader(zouwu.HeaderOrigin, "https://a.com").Expect().
+		Status(http.StatusOK).
+		Header(zouwu.HeaderAccessControlAllowOrigin, "*").
+		Header(zouwu.HeaderAccessControlExposeHeaders, "X-Total").
+		NoHeader(zouwu.HeaderVary).
+		Body("items")
+	client.GET("/items").Expect().
+		Status(http.StatusOK).
+		NoHeader(zouwu.HeaderAccessControlAllowOrigin)
+
+	// the allowed methods default to the routes of the path
+	client.OPTIONS("/items").
+		WithHeader(zouwu.HeaderOrigin, "https://a.com").
+		WithHeader(zouwu.HeaderAccessControlRequestMethod, http.MethodPost).
+		WithHeader(zouwu.HeaderAccessControlRequestHeaders, "X-Token").
+		Expect().
+		Status(http.StatusNoContent).
+		Header(zouwu.HeaderAccessControlAllowOrigin, "*").
+		HeaderContains(zouwu.HeaderAccessControlAllowMethods, http.MethodGet).
+		HeaderContains(zouwu.HeaderAccessControlAllowMethods, http.MethodPost).
+		Header(zouwu.HeaderAccessControlAllowHeaders, "X-Token").
+		NoHeader(zouwu.HeaderAccessControlExposeHeaders).
+		NoHeader(zouwu.HeaderAccessControlMaxAge).
+		Body("")
+	client.OPTIONS("/missing").
+		WithHeader(zouwu.HeaderOrigin, "https://a.com").
+		WithHeader(zouwu.HeaderAccessControlRequestMethod, http.MethodDelete).
+		Expect().
+		Status(http.StatusNoContent).
+		HeaderContains(zouwu.HeaderAccessControlAllowMethods, http.MethodDelete)
+}
+
+func TestCORSOrigins(t *testing.T) {
+	client := newClient(t, middleware.CORS(middleware.CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org/"},
+		AllowOriginFunc:  func(origin string) bool { return origin == "http://localhost:3000" },
+		AllowMethods:     []string{http.MethodGet},
+		AllowHeaders:     []string{"X-Token", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}), corsRoutes)
+
+	for _, origin := range []string{"https://example.com", "https://EXAMPLE.com", "https://api.example.org", "http://localhost:3000"} {
+		client.GET("/items").WithHeader(zouwu.HeaderOrigin, origin).Expect().
+			Header(zouwu.HeaderAccessControlAllowOrigin, origin).
+			Header(zouwu.HeaderAccessControlAllowCredentials, "true").
+			Header(zouwu.HeaderVary, zouwu.HeaderOrigin)
+	}
+	for _, origin := range []string{"https://evil.com", "https://example.org", "http://example.com", "https://example.com.evil.com"} {
+		client.GET("/items").WithHeader(zouwu.HeaderOrigin, origin).Expect().
+			Status(http.StatusOK).
+			NoHeader(zouwu.HeaderAccessControlAllowOrigin).
+			Body("items")
+	}
+
+	resp := client.OPTIONS("/items").
+		WithHeader(zouwu.HeaderOrigin, "https://api.example.org").
+		WithHeader(zouwu.HeaderAccessControlRequestMethod, http.MethodPost).
+		Expect().
+		Status(http.StatusNoContent).
+		Header(zouwu.HeaderAccessControlAllowOrigin, "https://api.example.org").
+		Header(zouwu.HeaderAccessControlAllowMethods, http.MethodGet).
+		Header(zouwu.HeaderAccessControlAllowHeaders, "X-Token, Content-Type").
+		Header(zouwu.HeaderAccessControlMaxAge, "3600")
+	vary := strings.Join(resp.Raw().Header.Values(zouwu.HeaderVary), ", ")
+	for _, want := range []string{zouwu.HeaderOrigin, zouwu.HeaderAccessControlRequestMethod, zouwu.HeaderAccessControlRequestHeaders} {
+		if !strings.Contains(vary, want) {
+			t.Errorf("Vary = %q, want it to contain %q", vary, want)
+		}
+	}
+
+	// preflights of other origins are answered without CORS headers
+	client.OPTIONS("/items").
+		WithHeader(zouwu.HeaderOrigin, "https://evil.com").
+		WithHeader(zouwu.HeaderAccessControlRequestMethod, http.MethodPost).
+		Expect().
+		Status(http.StatusNoContent).
+		NoHeader(zouwu.HeaderAccessControlAllowOrigin).
+		NoHeader(zouwu.HeaderAccessControlAllowMethods)
+}
+
+func TestCORSInvalidConfig(t *testing.T) {
+	for _, conf := range []middleware.CORSConfig{
+		{AllowCredentials: true},
+		{AllowOrigins: []string{"*"}, AllowCredentials: true},
+		{AllowOrigins: []string{"https://*example.com"}},
+		{AllowOrigins: []string{"https://*.*.example.com"}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%+v: want a panic", conf)
+				}
+			}()
+			middleware.CORS(conf)
+		}()
+	}
+}
@@ -0,0 +1,394 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/pkg/errors"
+)
+
+// JWTClaimsKey is the key of Context.Keys holding the JWTClaims verified by JWT.
+const JWTClaimsKey = "zouwu.jwt.claims"
+
+// Supported JWT algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// JWT verification errors
+var (
+	ErrJWTMalformed   = errors.New("malformed token")
+	ErrJWTAlgorithm   = errors.New("unexpected signing algorithm")
+	ErrJWTUnknownKey  = errors.New("unknown signing key")
+	ErrJWTSignature   = errors.New("invalid signature")
+	ErrJWTExpired     = errors.New("token is expired")
+	ErrJWTNotValidYet = errors.New("token is not valid yet")
+	ErrJWTAudience    = errors.New("invalid audience")
+	ErrJWTIssuer      = errors.New("invalid issuer")
+)
+
+// JWTClaims are the claims of a verified token, numbers are decoded as json.Number.
+type JWTClaims map[string]interface{}
+
+// Subject return the sub claim.
+func (c JWTClaims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+// JWTConfig defines the config of the JWT middleware and of NewJWTVerifier.
+// One of Secret, PublicKey or JWKSFile is required, Secret and PublicKey can not be both set.
+type JWTConfig struct {
+	// Secret is the HS256 key.
+	Secret []byte
+	// PublicKey is the RS256 *rsa.PublicKey or the ES256 *ecdsa.PublicKey on the P-256 curve.
+	PublicKey crypto.PublicKey
+	// JWKSFile is the path of a JSON Web Key Set, the key is selected by the kid of the token.
+	// RSA, P-256 EC and oct (HS256) keys are supported.
+	JWKSFile string
+	// Audience, when set, must be the aud claim or one of its values.
+	Audience string
+	// Issuer, when set, must be the iss claim.
+	Issuer string
+	// Leeway tolerates the clock skew when checking exp and nbf.
+	Leeway time.Duration
+	// QueryParam also looks the token up in this query parameter when the Authorization
+	// header has no Bearer token, e.g. for WebSocket handshakes.
+	QueryParam string
+	// Realm is sent in the WWW-Authenticate header, defaults to "Restricted".
+	Realm string
+	// ContextKey is the key of the JWTClaims in Context.Keys, defaults to JWTClaimsKey.
+	ContextKey string
+}
+
+// jwk is one key of the verifier, alg is the only algorithm it verifies
+type jwk struct {
+	alg string
+	key interface{}
+}
+
+// JWTVerifier verifies the signature and the registered claims of JWTs.
+type JWTVerifier struct {
+	conf JWTConfig
+	// keys are indexed by kid, "" is the key of the tokens without kid
+	keys map[string]jwk
+}
+
+// NewJWTVerifier return a JWTVerifier for the keys of config.
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	if len(config.Secret) > 0 && config.PublicKey != nil {
+		return nil, errors.New("[zouwu JWT]: Secret and PublicKey are exclusive")
+	}
+	v := &JWTVerifier{conf: config, keys: make(map[string]jwk)}
+	if len(config.Secret) > 0 {
+		v.keys[""] = jwk{alg: AlgHS256, key: config.Secret}
+	}
+	if config.PublicKey != nil {
+		key, err := publicJWK(config.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		v.keys[""] = key
+	}
+	if config.JWKSFile != "" {
+		keys, err := loadJWKS(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range keys {
+			v.keys[kid] = key
+		}
+		if _, ok := v.keys[""]; !ok && len(keys) == 1 {
+			for _, key := range keys {
+				v.keys[""] = key
+			}
+		}
+	}
+	if len(v.keys) == 0 {
+		return nil, errors.New("[zouwu JWT]: Secret, PublicKey or JWKSFile is required")
+	}
+	return v, nil
+}
+
+func publicJWK(key crypto.PublicKey) (jwk, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return jwk{alg: AlgRS256, key: k}, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return jwk{}, errors.New("[zouwu JWT]: ES256 requires a P-256 key")
+		}
+		return jwk{alg: AlgES256, key: k}, nil
+	}
+	return jwk{}, errors.Errorf("[zouwu JWT]: unsupported public key %T", key)
+}
+
+// Verify checks the signature of token, then its exp, nbf, aud and iss claims.
+func (v *JWTVerifier) Verify(token string) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	key, ok := v.keys[header.Kid]
+	if !ok {
+		return nil, ErrJWTUnknownKey
+	}
+	// the algorithm is bound to the key, never chosen by the token
+	if header.Alg != key.alg {
+		return nil, ErrJWTAlgorithm
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	if !verifyJWTSignature(key, parts[0]+"."+parts[1], signature) {
+		return nil, ErrJWTSignature
+	}
+
+	var claims JWTClaims
+	if err = decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err = v.checkClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *JWTVerifier) checkClaims(claims JWTClaims, now time.Time) error {
+	if exp, ok, err := numericDate(claims, "exp"); err != nil {
+		return err
+	} else if ok && !now.Before(exp.Add(v.conf.Leeway)) {
+		return ErrJWTExpired
+	}
+	if nbf, ok, err := numericDate(claims, "nbf"); err != nil {
+		return err
+	} else if ok && now.Add(v.conf.Leeway).Before(nbf) {
+		return ErrJWTNotValidYet
+	}
+	if v.conf.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.conf.Issuer {
+			return ErrJWTIssuer
+		}
+	}
+	if v.conf.Audience != "" && !hasAudience(claims["aud"], v.conf.Audience) {
+		return ErrJWTAudience
+	}
+	return nil
+}
+
+// numericDate return the time of the NumericDate claim name
+func numericDate(claims JWTClaims, name string) (time.Time, bool, error) {
+	value, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false, ErrJWTMalformed
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false, ErrJWTMalformed
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true, nil
+}
+
+// hasAudience checks the aud claim, a string or an array of strings
+func hasAudience(aud interface{}, audience string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == audience
+	case []interface{}:
+		for _, value := range a {
+			if s, ok := value.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return ErrJWTMalformed
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err = decoder.Decode(v); err != nil {
+		return ErrJWTMalformed
+	}
+	return nil
+}
+
+func verifyJWTSignature(key jwk, signingInput string, signature []byte) bool {
+	switch key.alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, key.key.([]byte))
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(signature, mac.Sum(nil))
+	case AlgRS256:
+		digest := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(key.key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case AlgES256:
+		// the signature is the concatenation of r and s, 32 bytes each
+		if len(signature) != 64 {
+			return false
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key.key.(*ecdsa.PublicKey), digest[:], r, s)
+	}
+	return false
+}
+
+// loadJWKS reads the JSON Web Key Set file path, keys are indexed by kid
+func loadJWKS(path string) (map[string]jwk, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "[zouwu JWT]: read JWKS")
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, "[zouwu JWT]: parse JWKS")
+	}
+
+	keys := make(map[string]jwk)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key jwk
+		switch k.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+				return nil, errors.Errorf("[zouwu JWT]: invalid RSA key %q", k.Kid)
+			}
+			exponent := 0
+			for _, b := range e {
+				exponent = exponent<<8 | int(b)
+			}
+			key = jwk{alg: AlgRS256, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}}
+		case "EC":
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if k.Crv != "P-256" || errX != nil || errY != nil {
+				return nil, errors.Errorf("[zouwu JWT]: invalid EC key %q", k.Kid)
+			}
+			pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+				return nil, errors.Errorf("[zouwu JWT]: invalid EC key %q", k.Kid)
+			}
+			key = jwk{alg: AlgES256, key: pub}
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return nil, errors.Errorf("[zouwu JWT]: invalid oct key %q", k.Kid)
+			}
+			key = jwk{alg: AlgHS256, key: secret}
+		default:
+			continue
+		}
+		if k.Alg != "" && k.Alg != key.alg {
+			return nil, errors.Errorf("[zouwu JWT]: unsupported algorithm %s for key %q", k.Alg, k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("[zouwu JWT]: JWKS has no supported signing key")
+	}
+	return keys, nil
+}
+
+// JWT returns a middleware verifying the Bearer token of the Authorization header, the claims
+// are stored in Context.Keys under JWTClaimsKey. The requests without a valid token are answered
+// with zouwu.ErrUnauthorized and a WWW-Authenticate header. It panics if the keys are invalid.
+//     api.Use(middleware.JWT(middleware.JWTConfig{
+//         JWKSFile: "/etc/app/jwks.json",
+//         Issuer:   "https://auth.example.com",
+//         Audience: "api",
+//     }))
+//     claims := ctx.MustGet(middleware.JWTClaimsKey).(middleware.JWTClaims)
+func JWT(config JWTConfig) zouwu.HandlerFunc {
+	verifier, err := NewJWTVerifier(config)
+	if err != nil {
+		panic(err)
+	}
+	conf := verifier.conf
+	if conf.Realm == "" {
+		conf.Realm = "Restricted"
+	}
+	if conf.ContextKey == "" {
+		conf.ContextKey = JWTClaimsKey
+	}
+	realm := "Bearer realm=" + strconv.Quote(conf.Realm)
+
+	return func(ctx *zouwu.Context) error {
+		token := bearerToken(ctx, conf.QueryParam)
+		if token == "" {
+			// RFC 6750: no error code when the request has no credentials
+			ctx.Ctx.Response.Header.Set(zouwu.HeaderWWWAuthenticate, realm)
+			return zouwu.ErrUnauthorized
+		}
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			ctx.Ctx.Response.Header.Set(zouwu.HeaderWWWAuthenticate,
+				realm+`, error="invalid_token", error_description=`+strconv.Quote(err.Error()))
+			return zouwu.ErrUnauthorized
+		}
+		ctx.Set(conf.ContextKey, claims)
+		return nil
+	}
+}
+
+// bearerToken return the Bearer token of the Authorization header, or of the query parameter
+func bearerToken(ctx *zouwu.Context, queryParam string) string {
+	const prefix = "Bearer "
+	header := string(ctx.Ctx.Request.Header.Peek(zouwu.HeaderAuthorization))
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	if queryParam != "" {
+		return ctx.Query(queryParam)
+	}
+	return ""
+}
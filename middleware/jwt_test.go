@@ -0,0 +1,249 @@
+package middleware_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/middleware"
+)
+
+var jwtSecret = []byte("0123456789abcdef0123456789abcdef")
+
+type jwtClaims map[string]interface{}
+
+// signJWT returns the token of claims signed with key, kid is omitted when empty
+func signJWT(t *testing.T, alg, kid string, key interface{}, claims jwtClaims) string {
+	t.Helper()
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+var jwtRoutes = map[string]zouwu.HandlerFunc{
+	"GET /": func(c *zouwu.Context) error {
+		return c.String(c.MustGet(middleware.JWTClaimsKey).(middleware.JWTClaims).Subject())
+	},
+}
+
+func bearer(token string) string {
+	return "Bearer " + token
+}
+
+func TestJWTHS256(t *testing.T) {
+	client := newClient(t, middleware.JWT(middleware.JWTConfig{Secret: jwtSecret, QueryParam: "access_token"}), jwtRoutes)
+	now := time.Now().Unix()
+
+	token := signJWT(t, middleware.AlgHS256, "", jwtSecret, jwtClaims{"sub": "bob", "exp": now + 60})
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(token)).Expect().
+		Status(http.StatusOK).
+		Body("bob")
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, "bearer "+token).Expect().Status(http.StatusOK)
+	client.GET("/").WithQuery("access_token", token).Expect().Status(http.StatusOK).Body("bob")
+
+	client.GET("/").Expect().
+		Status(http.StatusUnauthorized).
+		Header(zouwu.HeaderWWWAuthenticate, `Bearer realm="Restricted"`)
+
+	tests := []struct {
+		token string
+		err   error
+	}{
+		{"not.a-token", middleware.ErrJWTMalformed},
+		{signJWT(t, middleware.AlgHS256, "", []byte("other secret"), jwtClaims{"sub": "bob"}), middleware.ErrJWTSignature},
+		{signJWT(t, middleware.AlgHS256, "", jwtSecret, jwtClaims{"sub": "bob", "exp": now - 1}), middleware.ErrJWTExpired},
+		{signJWT(t, middleware.AlgHS256, "", jwtSecret, jwtClaims{"sub": "bob", "nbf": now + 60}), middleware.ErrJWTNotValidYet},
+		{signJWT(t, middleware.AlgHS256, "", jwtSecret, jwtClaims{"sub": "bob", "exp": "soon"}), middleware.ErrJWTMalformed},
+		{signJWT(t, middleware.AlgHS256, "k9", jwtSecret, jwtClaims{"sub": "bob"}), middleware.ErrJWTUnknownKey},
+		// the algorithm of the token can not replace the one of the key
+		{signJWT(t, "none", "", jwtSecret, jwtClaims{"sub": "bob"}), middleware.ErrJWTAlgorithm},
+	}
+	for _, tt := range tests {
+		client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(tt.token)).Expect().
+			Status(http.StatusUnauthorized).
+			Header(zouwu.HeaderWWWAuthenticate,
+				`Bearer realm="Restricted", error="invalid_token", error_description="`+tt.err.Error()+`"`)
+	}
+}
+
+func TestJWTClaims(t *testing.T) {
+	client := newClient(t, middleware.JWT(middleware.JWTConfig{
+		Secret:   jwtSecret,
+		Audience: "api",
+		Issuer:   "https://auth.example.com",
+		Leeway:   time.Minute,
+		Realm:    "API",
+	}), jwtRoutes)
+	now := time.Now().Unix()
+	valid := jwtClaims{"sub": "bob", "iss": "https://auth.example.com", "aud": "api"}
+
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(signJWT(t, middleware.AlgHS256, "", jwtSecret, valid))).Expect().
+		Status(http.StatusOK)
+	claims := jwtClaims{"sub": "bob", "iss": "https://auth.example.com", "aud": []string{"web", "api"}, "exp": now - 30, "nbf": now + 30}
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(signJWT(t, middleware.AlgHS256, "", jwtSecret, claims))).Expect().
+		Status(http.StatusOK).
+		Body("bob")
+
+	tests := []struct {
+		claims jwtClaims
+		err    error
+	}{
+		{jwtClaims{"iss": "https://auth.example.com", "aud": "web"}, middleware.ErrJWTAudience},
+		{jwtClaims{"iss": "https://auth.example.com"}, middleware.ErrJWTAudience},
+		{jwtClaims{"iss": "https://evil.com", "aud": "api"}, middleware.ErrJWTIssuer},
+		{jwtClaims{"iss": "https://auth.example.com", "aud": "api", "exp": now - 90}, middleware.ErrJWTExpired},
+	}
+	for _, tt := range tests {
+		client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(signJWT(t, middleware.AlgHS256, "", jwtSecret, tt.claims))).Expect().
+			Status(http.StatusUnauthorized).
+			Header(zouwu.HeaderWWWAuthenticate, `Bearer realm="API", error="invalid_token", error_description="`+tt.err.Error()+`"`)
+	}
+}
+
+func TestJWTPublicKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwtClaims{"sub": "bob"}
+
+	client := newClient(t, middleware.JWT(middleware.JWTConfig{PublicKey: &rsaKey.PublicKey}), jwtRoutes)
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(signJWT(t, middleware.AlgRS256, "", rsaKey, claims))).Expect().
+		Status(http.StatusOK).
+		Body("bob")
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(signJWT(t, middleware.AlgHS256, "", jwtSecret, claims))).Expect().
+		Status(http.StatusUnauthorized)
+
+	client = newClient(t, middleware.JWT(middleware.JWTConfig{PublicKey: &ecKey.PublicKey}), jwtRoutes)
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(signJWT(t, middleware.AlgES256, "", ecKey, claims))).Expect().
+		Status(http.StatusOK).
+		Body("bob")
+	client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(signJWT(t, middleware.AlgRS256, "", rsaKey, claims))).Expect().
+		Status(http.StatusUnauthorized)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = middleware.NewJWTVerifier(middleware.JWTConfig{PublicKey: &p384.PublicKey}); err == nil {
+		t.Error("want an error on the P-384 key")
+	}
+	if _, err = middleware.NewJWTVerifier(middleware.JWTConfig{}); err == nil {
+		t.Error("want an error without keys")
+	}
+	// the verifier would only keep one of them
+	if _, err = middleware.NewJWTVerifier(middleware.JWTConfig{Secret: jwtSecret, PublicKey: &rsaKey.PublicKey}); err == nil {
+		t.Error("want an error with both Secret and PublicKey")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("JWT() with both Secret and PublicKey did not panic")
+		}
+	}()
+	middleware.JWT(middleware.JWTConfig{Secret: jwtSecret, PublicKey: &ecKey.PublicKey})
+}
+
+func TestJWTJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := `{"keys":[
+		{"kty":"RSA","kid":"rsa1","alg":"RS256","use":"sig","n":"` + b64(rsaKey.N.Bytes()) + `","e":"` + b64(big.NewInt(int64(rsaKey.E)).Bytes()) + `"},
+		{"kty":"EC","kid":"ec1","crv":"P-256","x":"` + b64(ecKey.X.Bytes()) + `","y":"` + b64(ecKey.Y.Bytes()) + `"},
+		{"kty":"oct","kid":"hs1","k":"` + b64(jwtSecret) + `"},
+		{"kty":"RSA","kid":"enc1","use":"enc","n":"AQAB","e":"AQAB"},
+		{"kty":"OKP","kid":"ed1","crv":"Ed25519","x":"AA"}
+	]}`
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err = ioutil.WriteFile(path, []byte(jwks), 0600); err != nil {
+		t.Fatal(err)
+	}
+	client := newClient(t, middleware.JWT(middleware.JWTConfig{JWKSFile: path}), jwtRoutes)
+	claims := jwtClaims{"sub": "bob"}
+
+	for _, token := range []string{
+		signJWT(t, middleware.AlgRS256, "rsa1", rsaKey, claims),
+		signJWT(t, middleware.AlgES256, "ec1", ecKey, claims),
+		signJWT(t, middleware.AlgHS256, "hs1", jwtSecret, claims),
+	} {
+		client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(token)).Expect().
+			Status(http.StatusOK).
+			Body("bob")
+	}
+	for _, token := range []string{
+		// the key is selected by kid, several keys require one
+		signJWT(t, middleware.AlgRS256, "", rsaKey, claims),
+		signJWT(t, middleware.AlgRS256, "ec1", rsaKey, claims),
+		signJWT(t, middleware.AlgRS256, "enc1", rsaKey, claims),
+	} {
+		client.GET("/").WithHeader(zouwu.HeaderAuthorization, bearer(token)).Expect().
+			Status(http.StatusUnauthorized)
+	}
+
+	for _, invalid := range []string{
+		`{"keys":[]}`,
+		`{"keys":[{"kty":"RSA","kid":"rsa1","alg":"ES256","n":"AQAB","e":"AQAB"}]}`,
+		`{"keys":[{"kty":"EC","kid":"ec1","crv":"P-256","x":"AQ","y":"AQ"}]}`,
+		`not json`,
+	} {
+		if err = ioutil.WriteFile(path, []byte(invalid), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = middleware.NewJWTVerifier(middleware.JWTConfig{JWKSFile: path}); err == nil {
+			t.Errorf("%s: want an error", invalid)
+		}
+	}
+	if _, err = middleware.NewJWTVerifier(middleware.JWTConfig{JWKSFile: path + ".missing"}); err == nil {
+		t.Error("want an error on the missing JWKS file")
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strconv"
+
+	"github.com/DCRcoder/zouwu"
+)
+
+// APIKeyPrincipalKey is the key of Context.Keys holding the principal of the API key verified by KeyAuth.
+const APIKeyPrincipalKey = "zouwu.keyauth.principal"
+
+// KeyAuthConfig defines the config of the KeyAuth middleware.
+type KeyAuthConfig struct {
+	// Header carries the API key, defaults to X-API-Key.
+	Header string
+	// QueryParam also looks the key up in this query parameter when the header is missing.
+	QueryParam string
+	// Keys maps the API keys to their principal, e.g. the name of the client.
+	Keys map[string]interface{}
+	// Validator return the principal of the keys not found in Keys, ok is false for invalid keys.
+	// It should compare the keys in constant time.
+	Validator func(ctx *zouwu.Context, key string) (principal interface{}, ok bool)
+	// Realm is sent in the WWW-Authenticate header, defaults to "Restricted".
+	Realm string
+	// ContextKey is the key of the principal in Context.Keys, defaults to APIKeyPrincipalKey.
+	ContextKey string
+}
+
+// apiKey is the digest of one key of KeyAuthConfig.Keys
+type apiKey struct {
+	hash      [sha256.Size]byte
+	principal interface{}
+}
+
+// KeyAuth returns a middleware authenticating the requests by API key, the requests without
+// a valid key are answered with zouwu.ErrUnauthorized and a WWW-Authenticate header.
+//     api.Use(middleware.KeyAuth(middleware.KeyAuthConfig{
+//         Keys: map[string]interface{}{os.Getenv("BILLING_API_KEY"): "billing"},
+//     }))
+func KeyAuth(config KeyAuthConfig) zouwu.HandlerFunc {
+	conf := config
+	if len(conf.Keys) == 0 && conf.Validator == nil {
+		panic("[zouwu KeyAuth]: Keys or Validator is required")
+	}
+	if conf.Header == "" {
+		conf.Header = "X-API-Key"
+	}
+	if conf.Realm == "" {
+		conf.Realm = "Restricted"
+	}
+	if conf.ContextKey == "" {
+		conf.ContextKey = APIKeyPrincipalKey
+	}
+	keys := make([]apiKey, 0, len(conf.Keys))
+	for key, principal := range conf.Keys {
+		keys = append(keys, apiKey{hash: sha256.Sum256([]byte(key)), principal: principal})
+	}
+	challenge := "APIKey realm=" + strconv.Quote(conf.Realm) + ", header=" + strconv.Quote(conf.Header)
+
+	return func(ctx *zouwu.Context) error {
+		key := string(ctx.Ctx.Request.Header.Peek(conf.Header))
+		if key == "" && conf.QueryParam != "" {
+			key = ctx.Query(conf.QueryParam)
+		}
+		if key != "" {
+			if principal, ok := lookupAPIKey(ctx, &conf, keys, key); ok {
+				ctx.Set(conf.ContextKey, principal)
+				return nil
+			}
+		}
+		ctx.Ctx.Response.Header.Set(zouwu.HeaderWWWAuthenticate, challenge)
+		return zouwu.ErrUnauthorized
+	}
+}
+
+// lookupAPIKey compares key with every key so the time taken does not tell which one is closer
+func lookupAPIKey(ctx *zouwu.Context, conf *KeyAuthConfig, keys []apiKey, key string) (interface{}, bool) {
+	hash := sha256.Sum256([]byte(key))
+	var principal interface{}
+	found := false
+	for i := range keys {
+		if subtle.ConstantTimeCompare(hash[:], keys[i].hash[:]) == 1 {
+			principal, found = keys[i].principal, true
+		}
+	}
+	if found {
+		return principal, true
+	}
+	if conf.Validator != nil {
+		return conf.Validator(ctx, key)
+	}
+	return nil, false
+}
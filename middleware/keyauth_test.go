@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/middleware"
+)
+
+type apiClient struct {
+	Name string
+}
+
+func TestKeyAuth(t *testing.T) {
+	client := newClient(t, middleware.KeyAuth(middleware.KeyAuthConfig{
+		QueryParam: "api_key",
+		Keys:       map[string]interface{}{"k-billing": apiClient{"billing"}},
+		Validator: func(ctx *zouwu.Context, key string) (interface{}, bool) {
+			if key == "k-dynamic" {
+				return apiClient{"dynamic"}, true
+			}
+			return nil, false
+		},
+	}), map[string]zouwu.HandlerFunc{
+		"GET /": func(c *zouwu.Context) error {
+			return c.String(c.MustGet(middleware.APIKeyPrincipalKey).(apiClient).Name)
+		},
+	})
+
+	client.GET("/").WithHeader("X-API-Key", "k-billing").Expect().
+		Status(http.StatusOK).
+		Body("billing")
+	client.GET("/").WithHeader("X-API-Key", "k-dynamic").Expect().
+		Status(http.StatusOK).
+		Body("dynamic")
+	client.GET("/").WithQuery("api_key", "k-billing").Expect().
+		Status(http.StatusOK).
+		Body("billing")
+	// the header wins over the query parameter
+	client.GET("/").WithHeader("X-API-Key", "wrong").WithQuery("api_key", "k-billing").Expect().
+		Status(http.StatusUnauthorized)
+
+	challenge := `APIKey realm="Restricted", header="X-API-Key"`
+	client.GET("/").Expect().
+		Status(http.StatusUnauthorized).
+		Header(zouwu.HeaderWWWAuthenticate, challenge)
+	client.GET("/").WithHeader("X-API-Key", "k-unknown").Expect().
+		Status(http.StatusUnauthorized).
+		Header(zouwu.HeaderWWWAuthenticate, challenge)
+}
+
+func TestKeyAuthConfig(t *testing.T) {
+	client := newClient(t, middleware.KeyAuth(middleware.KeyAuthConfig{
+		Header:     "Api-Token",
+		Keys:       map[string]interface{}{"k1": "one"},
+		Realm:      "API",
+		ContextKey: "client",
+	}), map[string]zouwu.HandlerFunc{
+		"GET /": func(c *zouwu.Context) error { return c.String(c.MustGet("client").(string)) },
+	})
+
+	client.GET("/").WithHeader("Api-Token", "k1").Expect().Status(http.StatusOK).Body("one")
+	client.GET("/").WithHeader("X-API-Key", "k1").WithQuery("api_key", "k1").Expect().
+		Status(http.StatusUnauthorized).
+		Header(zouwu.HeaderWWWAuthenticate, `APIKey realm="API", header="Api-Token"`)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic without Keys and Validator")
+		}
+	}()
+	middleware.KeyAuth(middleware.KeyAuthConfig{})
+}
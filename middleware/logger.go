@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+)
+
+// Log formats of LoggerConfig.Format, any other format is a template where ${field} is
+// replaced by the value of field, e.g. "${status} ${method} ${path} ${latency}".
+// Empty values are written as a hyphen in templates, the values are escaped like strconv.Quote
+// so a request can not break the line or forge another one.
+//
+// The fields are time, method, route, path, uri, proto, host, status, latency, bytes_in,
+// bytes_out, ip, request_id, error, referer and user_agent.
+const (
+	// FormatCommon is the Common Log Format.
+	FormatCommon = `${ip} - - [${time}] "${method} ${uri} ${proto}" ${status} ${bytes_out}`
+	// FormatCombined is the Combined Log Format.
+	FormatCombined = FormatCommon + ` "${referer}" "${user_agent}"`
+	// FormatJSON writes one JSON object per request with the fields of LoggerConfig.Fields.
+	FormatJSON = "json"
+)
+
+// clfTimeFormat is the time format of the Common Log Format
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// LoggerConfig defines the config of the Logger middleware.
+type LoggerConfig struct {
+	// Format is FormatCommon, FormatCombined, FormatJSON or a template, defaults to FormatCommon.
+	Format string
+	// Fields selects the fields written by FormatJSON, defaults to all the fields.
+	Fields []string
+	// TimeFormat formats the time field, defaults to the Common Log Format time,
+	// or RFC3339 with FormatJSON.
+	TimeFormat string
+	// SkipPaths lists the request paths that are not logged, e.g. health checks.
+	SkipPaths []string
+	// Output receives one line per request, when nil the lines are written
+	// at info level by the Logger of the engine. The writes are serialized.
+	Output io.Writer
+}
+
+// logFields lists the fields in the order of the JSON format
+var logFields = []string{
+	"time", "method", "route", "path", "uri", "proto", "host", "status", "latency",
+	"bytes_in", "bytes_out", "ip", "request_id", "error", "referer", "user_agent",
+}
+
+// logSegment is a literal text or a field of the compiled template
+type logSegment struct {
+	text  string
+	field string
+}
+
+// Logger returns a middleware that writes an access log line for each request.
+//     router.Use(middleware.Logger(middleware.LoggerConfig{Format: middleware.FormatJSON, SkipPaths: []string{"/health"}}))
+func Logger(config ...LoggerConfig) zouwu.HandlerFunc {
+	var conf LoggerConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.Format == "" {
+		conf.Format = FormatCommon
+	}
+	if conf.TimeFormat == "" {
+		conf.TimeFormat = clfTimeFormat
+		if conf.Format == FormatJSON {
+			conf.TimeFormat = time.RFC3339
+		}
+	}
+	fields := conf.Fields
+	if len(fields) == 0 {
+		fields = logFields
+	}
+	for _, field := range fields {
+		checkLogField(field)
+	}
+	var segments []logSegment
+	if conf.Format != FormatJSON {
+		segments = compileLogFormat(conf.Format)
+	}
+	var mu sync.Mutex
+	skip := make(map[string]struct{}, len(conf.SkipPaths))
+	for _, path := range conf.SkipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(ctx *zouwu.Context) error {
+		if _, ok := skip[string(ctx.Ctx.Path())]; ok {
+			ctx.Next()
+			return nil
+		}
+		start := time.Now()
+		ctx.Next()
+		entry := &logEntry{ctx: ctx, start: start, latency: time.Since(start), timeFormat: conf.TimeFormat}
+
+		var line []byte
+		if conf.Format == FormatJSON {
+			line = entry.appendJSON(nil, fields)
+		} else {
+			line = entry.appendTemplate(nil, segments)
+		}
+		if conf.Output != nil {
+			mu.Lock()
+			conf.Output.Write(append(line, '\n'))
+			mu.Unlock()
+		} else {
+			ctx.Logger().Info(string(line))
+		}
+		return nil
+	}
+}
+
+func checkLogField(field string) {
+	for _, f := range logFields {
+		if f == field {
+			return
+		}
+	}
+	panic("[zouwu Logger]: unknown log field '" + field + "'")
+}
+
+// compileLogFormat splits format into literal texts and ${field} placeholders
+func compileLogFormat(format string) []logSegment {
+	var segments []logSegment
+	for {
+		start := strings.Index(format, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(format[start:], '}')
+		if end < 0 {
+			break
+		}
+		field := format[start+2 : start+end]
+		checkLogField(field)
+		if start > 0 {
+			segments = append(segments, logSegment{text: format[:start]})
+		}
+		segments = append(segments, logSegment{field: field})
+		format = format[start+end+1:]
+	}
+	if format != "" {
+		segments = append(segments, logSegment{text: format})
+	}
+	return segments
+}
+
+// logEntry computes the fields of one request
+type logEntry struct {
+	ctx        *zouwu.Context
+	start      time.Time
+	latency    time.Duration
+	timeFormat string
+}
+
+// value return the value of field, numeric values are not quoted in JSON
+func (e *logEntry) value(field string) (value string, numeric bool) {
+	rctx := e.ctx.Ctx
+	switch field {
+	case "time":
+		return e.start.Format(e.timeFormat), false
+	case "method":
+		return string(rctx.Method()), false
+	case "route":
+		return e.ctx.RoutePath, false
+	case "path":
+		return string(rctx.Path()), false
+	case "uri":
+		return string(rctx.RequestURI()), false
+	case "proto":
+		if rctx.Request.Header.IsHTTP11() {
+			return "HTTP/1.1", false
+		}
+		return "HTTP/1.0", false
+	case "host":
+		return string(rctx.Host()), false
+	case "status":
+		return strconv.Itoa(rctx.Response.StatusCode()), true
+	case "latency":
+		return e.latency.String(), false
+	case "bytes_in":
+		return strconv.Itoa(len(rctx.Request.Body())), true
+	case "bytes_out":
+		return strconv.Itoa(responseSize(e.ctx)), true
+	case "ip":
+		return rctx.RemoteIP().String(), false
+	case "request_id":
+		return e.ctx.RequestID(), false
+	case "error":
+		if e.ctx.Error != nil {
+			return e.ctx.Error.Error(), false
+		}
+		return "", false
+	case "referer":
+		return string(rctx.Request.Header.Peek(zouwu.HeaderReferer)), false
+	case "user_agent":
+		return string(rctx.Request.Header.UserAgent()), false
+	}
+	return "", false
+}
+
+func (e *logEntry) appendTemplate(dst []byte, segments []logSegment) []byte {
+	for _, segment := range segments {
+		if segment.field == "" {
+			dst = append(dst, segment.text...)
+			continue
+		}
+		value, _ := e.value(segment.field)
+		if value == "" || (segment.field == "bytes_out" && value == "0") {
+			// the Common Log Format writes a hyphen for missing values
+			value = "-"
+		}
+		dst = appendEscaped(dst, value)
+	}
+	return dst
+}
+
+func (e *logEntry) appendJSON(dst []byte, fields []string) []byte {
+	dst = append(dst, '{')
+	for i, field := range fields {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, '"')
+		dst = append(dst, field...)
+		dst = append(dst, '"', ':')
+		value, numeric := e.value(field)
+		if numeric {
+			dst = append(dst, value...)
+			continue
+		}
+		quoted, _ := json.Marshal(value)
+		dst = append(dst, quoted...)
+	}
+	return append(dst, '}')
+}
+
+// appendEscaped appends value quoted like strconv.Quote, without the surrounding quotes
+func appendEscaped(dst []byte, value string) []byte {
+	quoted := strconv.Quote(value)
+	return append(dst, quoted[1:len(quoted)-1]...)
+}
+
+// responseSize return the size of the response body without reading body streams
+func responseSize(ctx *zouwu.Context) int {
+	resp := &ctx.Ctx.Response
+	if resp.IsBodyStream() {
+		if size := resp.Header.ContentLength(); size > 0 {
+			return size
+		}
+		return 0
+	}
+	return len(resp.Body())
+}
@@ -0,0 +1,117 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/middleware"
+)
+
+var loggerRoutes = map[string]zouwu.HandlerFunc{
+	"GET /users/:id": func(c *zouwu.Context) error { return c.String("user " + c.URLParam("id")) },
+	"GET /fail": func(c *zouwu.Context) error {
+		c.Set(zouwu.RequestIDKey, "abc")
+		return zouwu.ErrForbidden
+	},
+	"GET /health": text("ok"),
+}
+
+func TestLoggerCommonFormat(t *testing.T) {
+	var out bytes.Buffer
+	client := newClient(t, middleware.Logger(middleware.LoggerConfig{Output: &out}), loggerRoutes)
+
+	client.GET("/users/42?x=1").Expect().Status(http.StatusOK)
+	line := out.String()
+	if !strings.HasSuffix(line, `"GET /users/42?x=1 HTTP/1.1" 200 7`+"\n") {
+		t.Errorf("line = %q, want a Common Log Format line", line)
+	}
+	if !strings.Contains(line, " - - [") {
+		t.Errorf("line = %q, want the CLF identity fields", line)
+	}
+}
+
+func TestLoggerTemplate(t *testing.T) {
+	var out bytes.Buffer
+	client := newClient(t, middleware.Logger(middleware.LoggerConfig{
+		Format:    "${method} ${route} ${status} ${request_id} ${error}",
+		SkipPaths: []string{"/health"},
+		Output:    &out,
+	}), loggerRoutes)
+
+	// the inbound header is not trusted as the request ID
+	client.GET("/users/42").WithHeader(zouwu.HeaderXRequestID, "forged").Expect().Status(http.StatusOK)
+	client.GET("/fail").Expect().Status(http.StatusForbidden)
+	client.GET("/health").Expect().Status(http.StatusOK)
+
+	want := "GET /users/:id 200 - -\n" +
+		"GET /fail 403 abc " + zouwu.ErrForbidden.Error() + "\n"
+	if out.String() != want {
+		t.Errorf("lines = %q, want %q", out.String(), want)
+	}
+}
+
+func TestLoggerEscape(t *testing.T) {
+	var out bytes.Buffer
+	client := newClient(t, middleware.Logger(middleware.LoggerConfig{
+		Format: middleware.FormatCombined,
+		Output: &out,
+	}), loggerRoutes)
+
+	client.GET(`/users/"1"`).
+		WithHeader("User-Agent", "agent\" 200 0\t\"forged").
+		WithHeader("Referer", `\`).
+		Expect().Status(http.StatusOK)
+	line := out.String()
+	want := `"GET /users/%221%22 HTTP/1.1" 200 8 "\\" "agent\" 200 0\t\"forged"` + "\n"
+	if !strings.HasSuffix(line, want) {
+		t.Errorf("line = %q, want the suffix %q", line, want)
+	}
+}
+
+func TestLoggerJSON(t *testing.T) {
+	var out bytes.Buffer
+	client := newClient(t, middleware.Logger(middleware.LoggerConfig{
+		Format: middleware.FormatJSON,
+		Fields: []string{"method", "path", "status", "bytes_out", "error"},
+		Output: &out,
+	}), loggerRoutes)
+
+	client.GET("/users/7").Expect().Status(http.StatusOK)
+	want := `{"method":"GET","path":"/users/7","status":200,"bytes_out":6,"error":""}` + "\n"
+	if out.String() != want {
+		t.Errorf("line = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	client.GET("/fail").Expect().Status(http.StatusForbidden)
+	var entry struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("line = %q: %v", out.String(), err)
+	}
+	if entry.Status != http.StatusForbidden || entry.Error != zouwu.ErrForbidden.Error() {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestLoggerUnknownField(t *testing.T) {
+	for _, conf := range []middleware.LoggerConfig{
+		{Format: "${nope}"},
+		{Format: middleware.FormatJSON, Fields: []string{"nope"}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%+v: want a panic on the unknown field", conf)
+				}
+			}()
+			middleware.Logger(conf)
+		}()
+	}
+}
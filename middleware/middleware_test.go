@@ -0,0 +1,28 @@
+package middleware_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/zouwutest"
+)
+
+// newClient returns a test client of an engine using mw,
+// routes maps "METHOD /path" to the handler of the route.
+func newClient(t *testing.T, mw zouwu.HandlerFunc, routes map[string]zouwu.HandlerFunc) *zouwutest.Client {
+	e := zouwu.NewServer()
+	e.Use(mw)
+	for route, handler := range routes {
+		parts := strings.SplitN(route, " ", 2)
+		e.Handle(parts[0], parts[1], handler)
+	}
+	return zouwutest.New(t, e)
+}
+
+// text returns a handler responding body
+func text(body string) zouwu.HandlerFunc {
+	return func(c *zouwu.Context) error {
+		return c.String(body)
+	}
+}
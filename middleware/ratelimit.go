@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+)
+
+// RateLimit headers
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// RateLimitAlgorithm selects how the requests are counted.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket refills Limit tokens per Window up to Burst tokens, each request takes one.
+	TokenBucket RateLimitAlgorithm = iota
+	// SlidingWindow allows Limit requests in any Window, the count of the previous window
+	// is weighted by its overlap with the sliding window.
+	SlidingWindow
+)
+
+// RateLimitConfig defines the config of the RateLimit middleware.
+type RateLimitConfig struct {
+	// Algorithm defaults to TokenBucket.
+	Algorithm RateLimitAlgorithm
+	// Limit is the number of requests allowed per Window, it is required.
+	Limit int
+	// Window defaults to one minute.
+	Window time.Duration
+	// Burst is the capacity of the token bucket, defaults to Limit.
+	Burst int
+	// KeyFunc return the key the requests are counted by, requests with an empty key
+	// are not limited. Defaults to KeyByIP.
+	KeyFunc func(ctx *zouwu.Context) string
+	// Store defaults to a MemoryStore.
+	Store RateLimitStore
+}
+
+// rateLimitResult is the decision for one request
+type rateLimitResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// RateLimit returns a middleware that answers zouwu.ErrTooManyRequests to the clients
+// exceeding the limit. Every response carries the RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers, the limited ones also carry Retry-After.
+// RateLimit-Limit reports Limit with both algorithms, Burst only bounds the unused tokens kept.
+// The requests are let through when the store fails, the error is logged.
+//     router.Use(middleware.RateLimit(middleware.RateLimitConfig{Limit: 100, Window: time.Minute}))
+//     login.Use(middleware.RateLimit(middleware.RateLimitConfig{
+//         Algorithm: middleware.SlidingWindow,
+//         Limit:     5,
+//         Window:    time.Minute,
+//         KeyFunc:   middleware.KeyByHeader("X-API-Key"),
+//     }))
+func RateLimit(config RateLimitConfig) zouwu.HandlerFunc {
+	conf := config
+	if conf.Limit <= 0 {
+		panic("[zouwu RateLimit]: Limit must be positive")
+	}
+	if conf.Window <= 0 {
+		conf.Window = time.Minute
+	}
+	if conf.Burst <= 0 {
+		conf.Burst = conf.Limit
+	}
+	if conf.KeyFunc == nil {
+		conf.KeyFunc = KeyByIP()
+	}
+	if conf.Store == nil {
+		conf.Store = NewMemoryStore()
+	}
+	var ttl time.Duration
+	var take func(state []byte, now time.Time) ([]byte, rateLimitResult)
+	switch conf.Algorithm {
+	case TokenBucket:
+		// a bucket left alone that long is full again, the same as a missing one
+		ttl = time.Duration(float64(conf.Window) * float64(conf.Burst) / float64(conf.Limit))
+		take = conf.takeToken
+	case SlidingWindow:
+		ttl = 2 * conf.Window
+		take = conf.countWindow
+	default:
+		panic("[zouwu RateLimit]: unknown algorithm")
+	}
+
+	return func(ctx *zouwu.Context) error {
+		key := conf.KeyFunc(ctx)
+		if key == "" {
+			return nil
+		}
+		var result rateLimitResult
+		err := conf.Store.Update(key, ttl, func(state []byte) []byte {
+			var next []byte
+			next, result = take(state, time.Now())
+			return next
+		})
+		if err != nil {
+			ctx.Logger().Errorf("[zouwu RateLimit]: update key %s: %v", key, err)
+			return nil
+		}
+
+		header := &ctx.Ctx.Response.Header
+		header.Set(HeaderRateLimitLimit, strconv.Itoa(result.limit))
+		header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.remaining))
+		header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.reset)))
+		if !result.allowed {
+			header.Set(zouwu.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.retryAfter)))
+			return zouwu.ErrTooManyRequests
+		}
+		return nil
+	}
+}
+
+// takeToken runs the token bucket, its state is the number of tokens and the time of the last update
+func (conf *RateLimitConfig) takeToken(state []byte, now time.Time) ([]byte, rateLimitResult) {
+	perToken := float64(conf.Window) / float64(conf.Limit)
+	capacity := float64(conf.Burst)
+	tokens := capacity
+	if len(state) == 16 {
+		tokens = math.Float64frombits(binary.BigEndian.Uint64(state[:8]))
+		last := int64(binary.BigEndian.Uint64(state[8:]))
+		if elapsed := now.UnixNano() - last; elapsed > 0 {
+			tokens = math.Min(capacity, tokens+float64(elapsed)/perToken)
+		}
+	}
+
+	result := rateLimitResult{limit: conf.Limit}
+	if tokens >= 1 {
+		tokens--
+		result.allowed = true
+	} else {
+		result.retryAfter = time.Duration((1 - tokens) * perToken)
+	}
+	result.remaining = int(tokens)
+	result.reset = time.Duration((capacity - tokens) * perToken)
+
+	next := make([]byte, 16)
+	binary.BigEndian.PutUint64(next[:8], math.Float64bits(tokens))
+	binary.BigEndian.PutUint64(next[8:], uint64(now.UnixNano()))
+	return next, result
+}
+
+// countWindow runs the sliding window, its state is the start of the current fixed window
+// and the counts of the previous and current windows
+func (conf *RateLimitConfig) countWindow(state []byte, now time.Time) ([]byte, rateLimitResult) {
+	window := int64(conf.Window)
+	nowNano := now.UnixNano()
+	start := nowNano - nowNano%window
+	var prev, curr int64
+	if len(state) == 24 {
+		stateStart := int64(binary.BigEndian.Uint64(state[:8]))
+		prev = int64(binary.BigEndian.Uint64(state[8:16]))
+		curr = int64(binary.BigEndian.Uint64(state[16:]))
+		switch start - stateStart {
+		case 0:
+		case window:
+			prev, curr = curr, 0
+		default:
+			prev, curr = 0, 0
+		}
+	}
+
+	elapsed := nowNano - start
+	weight := float64(window-elapsed) / float64(window)
+	count := float64(prev)*weight + float64(curr)
+	limit := float64(conf.Limit)
+	result := rateLimitResult{limit: conf.Limit, reset: time.Duration(window - elapsed)}
+	if count+1 <= limit {
+		curr++
+		count++
+		result.allowed = true
+	} else if curr+1 > int64(conf.Limit) || prev == 0 {
+		result.retryAfter = result.reset
+	} else {
+		// wait until the weight of the previous window leaves room for one request
+		at := float64(window) * (1 - (limit-float64(curr)-1)/float64(prev))
+		result.retryAfter = time.Duration(at) - time.Duration(elapsed)
+	}
+	result.remaining = int(math.Max(0, math.Floor(limit-count)))
+
+	next := make([]byte, 24)
+	binary.BigEndian.PutUint64(next[:8], uint64(start))
+	binary.BigEndian.PutUint64(next[8:16], uint64(prev))
+	binary.BigEndian.PutUint64(next[16:], uint64(curr))
+	return next, result
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// KeyByIP counts the requests by client IP.
+func KeyByIP() func(ctx *zouwu.Context) string {
+	return func(ctx *zouwu.Context) string {
+		return "ip:" + ctx.Ctx.RemoteIP().String()
+	}
+}
+
+// KeyByHeader counts the requests by the value of the header name,
+// requests without the header are not limited.
+func KeyByHeader(name string) func(ctx *zouwu.Context) string {
+	return func(ctx *zouwu.Context) string {
+		value := ctx.Ctx.Request.Header.Peek(name)
+		if len(value) == 0 {
+			return ""
+		}
+		return "header:" + name + ":" + string(value)
+	}
+}
+
+// KeyByRoute counts the requests by route and client IP, so each route has its own limit.
+func KeyByRoute() func(ctx *zouwu.Context) string {
+	return func(ctx *zouwu.Context) string {
+		return "route:" + string(ctx.Ctx.Method()) + " " + ctx.RoutePath + ":" + ctx.Ctx.RemoteIP().String()
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// RateLimitStore keeps the state of the rate limiters.
+// Implement it over a shared backend (Redis, memcached...) to apply the limits across instances.
+type RateLimitStore interface {
+	// Update calls fn with the state stored for key, nil when there is none or it expired,
+	// and stores the state returned by fn for ttl. The read, fn and the write must be atomic
+	// for a given key, e.g. with a compare-and-swap loop.
+	Update(key string, ttl time.Duration, fn func(state []byte) []byte) error
+}
+
+// memoryStoreShards is the number of shards of MemoryStore
+const memoryStoreShards = 64
+
+// memorySweepInterval is the number of updates of a shard between two sweeps of its expired keys
+const memorySweepInterval = 1024
+
+// MemoryStore is the in-memory RateLimitStore, its keys are spread over shards
+// so concurrent requests rarely wait on the same lock.
+type MemoryStore struct {
+	shards [memoryStoreShards]memoryShard
+}
+
+type memoryShard struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	updates int
+}
+
+type memoryEntry struct {
+	state   []byte
+	expires time.Time
+}
+
+// NewMemoryStore return an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]memoryEntry)
+	}
+	return s
+}
+
+// Update implements RateLimitStore.
+func (s *MemoryStore) Update(key string, ttl time.Duration, fn func(state []byte) []byte) error {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	shard := &s.shards[h.Sum32()%memoryStoreShards]
+
+	now := time.Now()
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	var state []byte
+	if entry, ok := shard.entries[key]; ok && now.Before(entry.expires) {
+		state = entry.state
+	}
+	shard.entries[key] = memoryEntry{state: fn(state), expires: now.Add(ttl)}
+
+	shard.updates++
+	if shard.updates >= memorySweepInterval {
+		shard.updates = 0
+		for k, entry := range shard.entries {
+			if !now.Before(entry.expires) {
+				delete(shard.entries, k)
+			}
+		}
+	}
+	return nil
+}
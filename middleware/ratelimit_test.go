@@ -0,0 +1,153 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/middleware"
+)
+
+var rateLimitRoutes = map[string]zouwu.HandlerFunc{
+	"GET /a": text("a"),
+	"GET /b": text("b"),
+}
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	client := newClient(t, middleware.RateLimit(middleware.RateLimitConfig{Limit: 2, Window: time.Hour}), rateLimitRoutes)
+
+	client.GET("/a").Expect().
+		Status(http.StatusOK).
+		Header(middleware.HeaderRateLimitLimit, "2").
+		Header(middleware.HeaderRateLimitRemaining, "1").
+		Header(middleware.HeaderRateLimitReset, "1800").
+		NoHeader(zouwu.HeaderRetryAfter).
+		Body("a")
+	client.GET("/b").Expect().
+		Status(http.StatusOK).
+		Header(middleware.HeaderRateLimitRemaining, "0").
+		Header(middleware.HeaderRateLimitReset, "3600")
+	client.GET("/a").Expect().
+		Status(http.StatusTooManyRequests).
+		Header(middleware.HeaderRateLimitRemaining, "0").
+		Header(zouwu.HeaderRetryAfter, "1800")
+}
+
+func TestRateLimitBurstRefill(t *testing.T) {
+	client := newClient(t, middleware.RateLimit(middleware.RateLimitConfig{
+		Limit:  20,
+		Window: time.Second,
+		Burst:  1,
+	}), rateLimitRoutes)
+
+	// the limit is the quota of the window, not the burst
+	client.GET("/a").Expect().Status(http.StatusOK).Header(middleware.HeaderRateLimitLimit, "20")
+	client.GET("/a").Expect().Status(http.StatusTooManyRequests).Header(zouwu.HeaderRetryAfter, "1")
+	// a token is refilled every 50ms
+	time.Sleep(60 * time.Millisecond)
+	client.GET("/a").Expect().Status(http.StatusOK)
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	client := newClient(t, middleware.RateLimit(middleware.RateLimitConfig{
+		Algorithm: middleware.SlidingWindow,
+		Limit:     2,
+		Window:    time.Hour,
+	}), rateLimitRoutes)
+
+	client.GET("/a").Expect().Status(http.StatusOK).
+		Header(middleware.HeaderRateLimitLimit, "2").
+		Header(middleware.HeaderRateLimitRemaining, "1")
+	client.GET("/a").Expect().Status(http.StatusOK).Header(middleware.HeaderRateLimitRemaining, "0")
+	resp := client.GET("/a").Expect().Status(http.StatusTooManyRequests)
+	reset, _ := strconv.Atoi(resp.Raw().Header.Get(middleware.HeaderRateLimitReset))
+	retry, _ := strconv.Atoi(resp.Raw().Header.Get(zouwu.HeaderRetryAfter))
+	// the window is aligned on the hour, the request is limited until it ends
+	if reset <= 0 || reset > 3600 || retry != reset {
+		t.Errorf("reset = %d, retry after = %d, want the end of the window", reset, retry)
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	client := newClient(t, middleware.RateLimit(middleware.RateLimitConfig{
+		Limit:   1,
+		Window:  time.Hour,
+		KeyFunc: middleware.KeyByHeader("X-API-Key"),
+	}), rateLimitRoutes)
+	client.GET("/a").WithHeader("X-API-Key", "k1").Expect().Status(http.StatusOK)
+	client.GET("/a").WithHeader("X-API-Key", "k1").Expect().Status(http.StatusTooManyRequests)
+	client.GET("/a").WithHeader("X-API-Key", "k2").Expect().Status(http.StatusOK)
+	// requests without a key are not limited
+	for i := 0; i < 3; i++ {
+		client.GET("/a").Expect().Status(http.StatusOK).NoHeader(middleware.HeaderRateLimitLimit)
+	}
+
+	client = newClient(t, middleware.RateLimit(middleware.RateLimitConfig{
+		Limit:   1,
+		Window:  time.Hour,
+		KeyFunc: middleware.KeyByRoute(),
+	}), rateLimitRoutes)
+	client.GET("/a").Expect().Status(http.StatusOK)
+	client.GET("/b").Expect().Status(http.StatusOK)
+	client.GET("/a").Expect().Status(http.StatusTooManyRequests)
+}
+
+// failingStore is a RateLimitStore whose backend is down
+type failingStore struct{}
+
+func (failingStore) Update(key string, ttl time.Duration, fn func(state []byte) []byte) error {
+	return errors.New("connection refused")
+}
+
+func TestRateLimitStoreError(t *testing.T) {
+	client := newClient(t, middleware.RateLimit(middleware.RateLimitConfig{
+		Limit: 1,
+		Store: failingStore{},
+	}), rateLimitRoutes)
+	for i := 0; i < 3; i++ {
+		client.GET("/a").Expect().Status(http.StatusOK).NoHeader(middleware.HeaderRateLimitLimit)
+	}
+}
+
+func TestRateLimitInvalidConfig(t *testing.T) {
+	for _, conf := range []middleware.RateLimitConfig{
+		{},
+		{Limit: 1, Algorithm: middleware.RateLimitAlgorithm(9)},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%+v: want a panic", conf)
+				}
+			}()
+			middleware.RateLimit(conf)
+		}()
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	store := middleware.NewMemoryStore()
+	update := func(ttl time.Duration) (prev []byte) {
+		err := store.Update("k", ttl, func(state []byte) []byte {
+			prev = state
+			return []byte("v")
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return prev
+	}
+	if prev := update(20 * time.Millisecond); prev != nil {
+		t.Errorf("first state = %q, want nil", prev)
+	}
+	if prev := update(20 * time.Millisecond); string(prev) != "v" {
+		t.Errorf("stored state = %q, want %q", prev, "v")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if prev := update(20 * time.Millisecond); prev != nil {
+		t.Errorf("expired state = %q, want nil", prev)
+	}
+}
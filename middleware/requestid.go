@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+)
+
+// maxRequestIDLength bounds the inbound request IDs that are reused
+const maxRequestIDLength = 128
+
+// RequestIDConfig defines the config of the RequestID middleware.
+type RequestIDConfig struct {
+	// Header carries the request ID, defaults to X-Request-ID.
+	Header string
+	// Generator returns the ID of the requests without a valid inbound ID, defaults to UUIDv4.
+	Generator func() string
+}
+
+// RequestID returns a middleware that gives every request an ID: the inbound header is reused
+// when it holds at most 128 printable ASCII characters, otherwise a new ID is generated.
+// The ID is stored in Context.Keys under zouwu.RequestIDKey, see Context.RequestID,
+// and echoed on the response header.
+//     router.Use(middleware.RequestID(middleware.RequestIDConfig{Generator: middleware.ULID}))
+//     router.Use(middleware.Logger())
+func RequestID(config ...RequestIDConfig) zouwu.HandlerFunc {
+	var conf RequestIDConfig
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.Header == "" {
+		conf.Header = zouwu.HeaderXRequestID
+	}
+	if conf.Generator == nil {
+		conf.Generator = UUIDv4
+	}
+
+	return func(ctx *zouwu.Context) error {
+		id := string(ctx.Ctx.Request.Header.Peek(conf.Header))
+		if !validRequestID(id) {
+			id = conf.Generator()
+		}
+		ctx.Set(zouwu.RequestIDKey, id)
+		ctx.Ctx.Response.Header.Set(conf.Header, id)
+		ctx.Next()
+		return nil
+	}
+}
+
+// validRequestID rejects the IDs that could forge log lines or headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// UUIDv4 returns a random UUID, e.g. 0b2c8f4e-6b1d-4c1a-9e1f-3d2a5b7c9e0f.
+func UUIDv4() string {
+	var u [16]byte
+	randomBytes(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// crockford is the base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID returns a lexicographically sortable ID made of the time in milliseconds
+// and 80 random bits, e.g. 01ARZ3NDEKTSV4RRFFQ69G5FAV.
+func ULID() string {
+	var u [16]byte
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(u[:6], ts[2:])
+	randomBytes(u[6:])
+	return encodeULID(u)
+}
+
+func encodeULID(u [16]byte) string {
+	// 128 bits are encoded in 26 characters of 5 bits, the first one holds 3 bits
+	var buf [26]byte
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic("[zouwu RequestID]: read random bytes: " + err.Error())
+	}
+}
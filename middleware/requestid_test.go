@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/middleware"
+)
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulidPattern = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+var requestIDRoutes = map[string]zouwu.HandlerFunc{
+	"GET /": func(c *zouwu.Context) error { return c.String(c.RequestID()) },
+}
+
+func TestRequestID(t *testing.T) {
+	client := newClient(t, middleware.RequestID(), requestIDRoutes)
+
+	resp := client.GET("/").Expect()
+	id := resp.Raw().Header.Get(zouwu.HeaderXRequestID)
+	if !uuidPattern.MatchString(id) {
+		t.Errorf("generated ID = %q, want a UUIDv4", id)
+	}
+	resp.Body(id)
+
+	client.GET("/").WithHeader(zouwu.HeaderXRequestID, "abc-123").Expect().
+		Header(zouwu.HeaderXRequestID, "abc-123").
+		Body("abc-123")
+}
+
+func TestRequestIDRejectsInvalidIDs(t *testing.T) {
+	client := newClient(t, middleware.RequestID(middleware.RequestIDConfig{
+		Header:    "X-Trace-ID",
+		Generator: func() string { return "generated" },
+	}), requestIDRoutes)
+
+	for _, id := range []string{"with space", "tab\there", "caf\xc3\xa9", strings.Repeat("a", 129)} {
+		client.GET("/").WithHeader("X-Trace-ID", id).Expect().
+			Header("X-Trace-ID", "generated").
+			Body("generated")
+	}
+	long := strings.Repeat("a", 128)
+	client.GET("/").WithHeader("X-Trace-ID", long).Expect().Header("X-Trace-ID", long)
+	client.GET("/").WithHeader(zouwu.HeaderXRequestID, "other").Expect().
+		Header("X-Trace-ID", "generated").
+		NoHeader(zouwu.HeaderXRequestID)
+}
+
+func TestUUIDv4(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := middleware.UUIDv4()
+		if !uuidPattern.MatchString(id) {
+			t.Fatalf("UUIDv4() = %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("UUIDv4() repeated %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestULID(t *testing.T) {
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id := middleware.ULID()
+		if !ulidPattern.MatchString(id) {
+			t.Fatalf("ULID() = %q", id)
+		}
+		ids = append(ids, id)
+		time.Sleep(2 * time.Millisecond)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("ULIDs = %q, want them sorted by time", ids)
+	}
+}
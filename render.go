@@ -0,0 +1,222 @@
+package zouwu
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+// Renderer encodes the response data for one media type.
+// Register custom Renderer with Engine.RegisterRenderer to support more formats (msgpack, protobuf...).
+type Renderer interface {
+	// ContentType return the Content-Type header of the rendered response.
+	ContentType() string
+	Render(data interface{}) ([]byte, error)
+}
+
+// Built-in renderers
+var (
+	JSONRenderer Renderer = jsonRender{}
+	XMLRenderer  Renderer = xmlRender{}
+	TextRenderer Renderer = textRender{contentType: MIMETextPlainCharsetUTF8}
+	HTMLRenderer Renderer = htmlEscapeRender{}
+)
+
+type jsonRender struct{}
+
+func (jsonRender) ContentType() string {
+	return MIMEApplicationJSON
+}
+
+func (jsonRender) Render(data interface{}) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+type xmlRender struct{}
+
+func (xmlRender) ContentType() string {
+	return MIMEApplicationXMLCharsetUTF8
+}
+
+func (xmlRender) Render(data interface{}) ([]byte, error) {
+	return xml.Marshal(data)
+}
+
+// textRender writes strings and byte slices as is, other values are formatted by fmt.
+type textRender struct {
+	contentType string
+}
+
+func (r textRender) ContentType() string {
+	return r.contentType
+}
+
+func (textRender) Render(data interface{}) ([]byte, error) {
+	switch v := data.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return []byte(fmt.Sprint(data)), nil
+}
+
+// htmlEscapeRender writes data formatted like textRender as escaped HTML,
+// template.HTML values are trusted and written as is.
+type htmlEscapeRender struct{}
+
+func (htmlEscapeRender) ContentType() string {
+	return MIMETextHTMLCharsetUTF8
+}
+
+func (htmlEscapeRender) Render(data interface{}) ([]byte, error) {
+	switch v := data.(type) {
+	case template.HTML:
+		return []byte(v), nil
+	case []byte:
+		return []byte(template.HTMLEscapeString(string(v))), nil
+	}
+	return []byte(template.HTMLEscapeString(fmt.Sprint(data))), nil
+}
+
+// renderers keeps the registered renderers in registration order,
+// the order decides the preferred media type when the client accepts several equally.
+type renderers struct {
+	offers []string
+	byType map[string]Renderer
+}
+
+func defaultRenderers() *renderers {
+	rs := &renderers{byType: make(map[string]Renderer)}
+	rs.register(MIMEApplicationJSON, JSONRenderer)
+	rs.register(MIMEApplicationXML, XMLRenderer)
+	rs.register(MIMETextXML, XMLRenderer)
+	rs.register(MIMETextPlain, TextRenderer)
+	rs.register(MIMETextHTML, HTMLRenderer)
+	return rs
+}
+
+func (rs *renderers) register(mediaType string, r Renderer) {
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := rs.byType[mediaType]; !ok {
+		rs.offers = append(rs.offers, mediaType)
+	}
+	rs.byType[mediaType] = r
+}
+
+// acceptRange is one media range of the Accept header
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses the Accept header, ranges with invalid syntax are ignored
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		slash := strings.IndexByte(mediaType, '/')
+		if slash <= 0 || slash == len(mediaType)-1 {
+			continue
+		}
+		ar := acceptRange{typ: mediaType[:slash], subtype: mediaType[slash+1:], q: 1}
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.ToLower(kv[0]) == "q" {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					ar.q = q
+				}
+			}
+		}
+		ranges = append(ranges, ar)
+	}
+	return ranges
+}
+
+// quality return the q value the ranges give to mediaType, taken from the most specific matching range.
+func quality(ranges []acceptRange, mediaType string) float64 {
+	slash := strings.IndexByte(mediaType, '/')
+	if slash < 0 {
+		return 0
+	}
+	typ, subtype := mediaType[:slash], mediaType[slash+1:]
+	q, specificity := 0.0, -1
+	for _, ar := range ranges {
+		s := -1
+		switch {
+		case ar.typ == typ && ar.subtype == subtype:
+			s = 2
+		case ar.typ == typ && ar.subtype == "*":
+			s = 1
+		case ar.typ == "*" && ar.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = ar.q, s
+		}
+	}
+	return q
+}
+
+// negotiate return the offer with the highest quality in the Accept header,
+// ties are won by the first offer. It returns "" when no offer is acceptable.
+func negotiate(accept string, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := quality(ranges, strings.ToLower(offer)); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// negotiateEncoding return the supported encoding with the highest q-value in header,
+// ties are won by the first supported encoding. It returns "" when the body must not be encoded.
+func negotiateEncoding(header string, supported []string) string {
+	if strings.TrimSpace(header) == "" {
+		return ""
+	}
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.ToLower(kv[0]) == "q" {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qs[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range supported {
+		q, ok := qs[encoding]
+		if !ok {
+			q, ok = qs["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	if identity, ok := qs["identity"]; ok && identity > bestQ {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,124 @@
+package zouwu
+
+import (
+	"html/template"
+	"net/http"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := defaultRenderers().offers
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", MIMEApplicationJSON},
+		{"*/*", MIMEApplicationJSON},
+		{"application/xml;q=0.9, */*;q=0.1", MIMEApplicationXML},
+		{"text/plain, application/json;q=0.5", MIMETextPlain},
+		{"text/*", MIMETextXML},
+		{"text/html", MIMETextHTML},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", MIMETextHTML},
+		{"image/png", ""},
+		{"application/json;q=0", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiate(tt.accept, offers); got != tt.want {
+			t.Errorf("negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+type renderUser struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func (u renderUser) String() string {
+	return "user " + u.Name
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		accept      string
+		data        interface{}
+		code        int
+		contentType string
+		body        string
+	}{
+		{"", renderUser{"a"}, http.StatusCreated, MIMEApplicationJSON, `{"name":"a"}`},
+		{"application/xml", renderUser{"a"}, http.StatusCreated, MIMEApplicationXMLCharsetUTF8, `<renderUser><name>a</name></renderUser>`},
+		{"text/plain", renderUser{"<b>"}, http.StatusCreated, MIMETextPlainCharsetUTF8, "user <b>"},
+		{"text/html", renderUser{"<b>"}, http.StatusCreated, MIMETextHTMLCharsetUTF8, "user &lt;b&gt;"},
+		{"text/html", template.HTML("<b>ok</b>"), http.StatusCreated, MIMETextHTMLCharsetUTF8, "<b>ok</b>"},
+		{"image/png", renderUser{"a"}, http.StatusNotAcceptable, MIMETextPlainCharsetUTF8, ErrNotAcceptable.Error()},
+	}
+	for _, tt := range tests {
+		e := NewServer()
+		e.GET("/", func(c *Context) error {
+			return c.Render(http.StatusCreated, tt.data)
+		})
+		req := newRequest(http.MethodGet, "/")
+		req.Header.Set(HeaderAccept, tt.accept)
+		resp := performRequest(e, req)
+		if resp.StatusCode() != tt.code {
+			t.Errorf("Accept %q: status = %d, want %d", tt.accept, resp.StatusCode(), tt.code)
+		}
+		if ct := string(resp.Header.ContentType()); ct != tt.contentType {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", tt.accept, ct, tt.contentType)
+		}
+		if body := string(resp.Body()); body != tt.body {
+			t.Errorf("Accept %q: body = %q, want %q", tt.accept, body, tt.body)
+		}
+		if vary := string(resp.Header.Peek(HeaderVary)); vary != HeaderAccept {
+			t.Errorf("Accept %q: Vary = %q, want %q", tt.accept, vary, HeaderAccept)
+		}
+	}
+}
+
+type upperRender struct{}
+
+func (upperRender) ContentType() string {
+	return "application/x-upper"
+}
+
+func (upperRender) Render(data interface{}) ([]byte, error) {
+	return []byte("UPPER"), nil
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	e := NewServer()
+	e.RegisterRenderer("application/x-upper", upperRender{})
+	e.GET("/", func(c *Context) error {
+		return c.Render(http.StatusOK, "data")
+	})
+	req := newRequest(http.MethodGet, "/")
+	req.Header.Set(HeaderAccept, "application/x-upper")
+	resp := performRequest(e, req)
+	if body := string(resp.Body()); body != "UPPER" {
+		t.Errorf("body = %q, want %q", body, "UPPER")
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{"br", "gzip"}
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"gzip, br", "br"},
+		{"gzip;q=1, br;q=0.5", "gzip"},
+		{"gzip;q=0", ""},
+		{"br;q=0, gzip;q=0", ""},
+		{"*", "br"},
+		{"*;q=0.5, br;q=0", "gzip"},
+		{"identity, gzip;q=0.5", ""},
+		{"deflate", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header, supported); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -11,6 +11,7 @@ type IRouter interface {
 // IRoutes http router interface.
 type IRoutes interface {
 	Use(...HandlerFunc) IRoutes
+	Name(string) IRoutes
 
 	Handle(string, string, ...HandlerFunc) IRoutes
 	HEAD(string, ...HandlerFunc) IRoutes
@@ -28,6 +29,10 @@ type RouterGroup struct {
 	engine     *Engine
 	root       bool
 	baseConfig *MethodConfig
+	// lastRoutes are the indexes in Engine.routes of the routes registered by the last call, see Name
+	lastRoutes []int
+	// host is set on the groups created by Engine.Host
+	host *virtualHost
 }
 
 var _ IRouter = &RouterGroup{}
@@ -53,6 +58,7 @@ func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *R
 		basePath: group.calculateAbsolutePath(relativePath),
 		engine:   group.engine,
 		root:     false,
+		host:     group.host,
 	}
 }
 
@@ -78,7 +84,7 @@ func (group *RouterGroup) combineHandlers(handlerGroups ...[]HandlerFunc) []Hand
 	return mergedHandlers
 }
 
-// SetMethodConfig is used to set config on specified method
+// SetMethodConfig is used to set config on the routes registered by the group after it
 func (group *RouterGroup) SetMethodConfig(config *MethodConfig) *RouterGroup {
 	group.baseConfig = config
 	return group
@@ -92,9 +98,14 @@ func (group *RouterGroup) BasePath() string {
 func (group *RouterGroup) handle(httpMethod, relativePath string, handlers ...HandlerFunc) IRoutes {
 	absolutePath := group.calculateAbsolutePath(relativePath)
 	handlers = group.combineHandlers(handlers)
-	group.engine.addRoute(httpMethod, absolutePath, handlers...)
+	group.engine.addRoute(group.host, httpMethod, absolutePath, handlers...)
+	group.lastRoutes = []int{group.engine.routeCount() - 1}
 	if group.baseConfig != nil {
-		group.engine.SetMethodConfig(absolutePath, group.baseConfig)
+		key := methodConfigKey{method: httpMethod, path: absolutePath}
+		if group.host != nil {
+			key.host = group.host.pattern
+		}
+		group.engine.setMethodConfig(key, group.baseConfig)
 	}
 	return group.returnObj()
 }
@@ -154,14 +165,19 @@ func (group *RouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) IRo
 // Any registers a route that matches all the HTTP methods.
 // GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE.
 func (group *RouterGroup) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
-	group.handle("GET", relativePath, handlers...)
-	group.handle("POST", relativePath, handlers...)
-	group.handle("PUT", relativePath, handlers...)
-	group.handle("PATCH", relativePath, handlers...)
-	group.handle("HEAD", relativePath, handlers...)
-	group.handle("OPTIONS", relativePath, handlers...)
-	group.handle("DELETE", relativePath, handlers...)
-	group.handle("CONNECT", relativePath, handlers...)
-	group.handle("TRACE", relativePath, handlers...)
+	return group.handleMethods([]string{"GET", "POST", "PUT", "PATCH", "HEAD", "OPTIONS", "DELETE", "CONNECT", "TRACE"},
+		relativePath, handlers...)
+}
+
+// handleMethods registers the route for each of methods, Name then names all of them.
+func (group *RouterGroup) handleMethods(methods []string, relativePath string, handlers ...HandlerFunc) IRoutes {
+	first := group.engine.routeCount()
+	for _, method := range methods {
+		group.handle(method, relativePath, handlers...)
+	}
+	group.lastRoutes = make([]int, 0, len(methods))
+	for i := first; i < group.engine.routeCount(); i++ {
+		group.lastRoutes = append(group.lastRoutes, i)
+	}
 	return group.returnObj()
 }
@@ -0,0 +1,187 @@
+package zouwu
+
+import (
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	// Host is the pattern given to Engine.Host, empty for the routes of the default host
+	Host   string
+	Method string
+	Path   string
+	// Name is set by RouterGroup.Name
+	Name string
+	// Handler is the name of the last handler of the chain, Handlers the names of the whole chain
+	Handler  string
+	Handlers []string
+	// Middlewares is the number of handlers running before Handler
+	Middlewares int
+	// Config is the MethodConfig set on the route, nil if none
+	Config *MethodConfig
+}
+
+// Routes returns the registered routes in registration order.
+func (engine *Engine) Routes() []RouteInfo {
+	engine.pcLock.RLock()
+	defer engine.pcLock.RUnlock()
+	routes := make([]RouteInfo, len(engine.routes))
+	for i, route := range engine.routes {
+		route.Config = engine.methodConfig(route.Host, route.Method, route.Path)
+		route.Handlers = append([]string(nil), route.Handlers...)
+		routes[i] = route
+	}
+	return routes
+}
+
+// logRoutes prints the route table through the engine logger, it is called in debug mode
+// so the table is printed at info level whatever the logger level.
+func (engine *Engine) logRoutes() {
+	routes := engine.Routes()
+	engine.logger.Infof("[zouwu Engine]: %d routes registered", len(routes))
+	for _, route := range routes {
+		timeout := ""
+		if route.Config != nil {
+			timeout = " timeout=" + route.Config.Timeout.String()
+		}
+		name := ""
+		if route.Name != "" {
+			name = " name=" + route.Name
+		}
+		engine.logger.Infof("[zouwu Engine]: %-7s %-40s --> %s (%d middlewares)%s%s",
+			route.Method, route.Host+route.Path, route.Handler, route.Middlewares, name, timeout)
+	}
+}
+
+func (engine *Engine) recordRoute(host *virtualHost, method, path string, handlers []HandlerFunc) {
+	names := make([]string, len(handlers))
+	for i, handler := range handlers {
+		names[i] = nameOfFunction(handler)
+	}
+	hostPattern := ""
+	if host != nil {
+		hostPattern = host.pattern
+	}
+	engine.pcLock.Lock()
+	engine.routes = append(engine.routes, RouteInfo{
+		Host:        hostPattern,
+		Method:      method,
+		Path:        path,
+		Handler:     names[len(names)-1],
+		Handlers:    names,
+		Middlewares: len(handlers) - 1,
+	})
+	engine.pcLock.Unlock()
+}
+
+// routeCount return the number of registered routes
+func (engine *Engine) routeCount() int {
+	engine.pcLock.RLock()
+	defer engine.pcLock.RUnlock()
+	return len(engine.routes)
+}
+
+func nameOfFunction(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// Name names the route registered last on the group, so its URL can be built by Engine.URL.
+// After Any, Static, StaticFS and StaticFile, all the routes they registered are named.
+//     router.GET("/user/:id", showUser).Name("user.show")
+// It panics if the group has no route or the name is already used.
+func (group *RouterGroup) Name(name string) IRoutes {
+	if len(group.lastRoutes) == 0 {
+		panic("[zouwu Engine]: no route to name '" + name + "'")
+	}
+	group.engine.nameRoute(name, group.lastRoutes)
+	return group.returnObj()
+}
+
+func (engine *Engine) nameRoute(name string, routes []int) {
+	engine.pcLock.Lock()
+	defer engine.pcLock.Unlock()
+	if _, ok := engine.routeNames[name]; ok {
+		panic("[zouwu Engine]: route name '" + name + "' is already used")
+	}
+	engine.routeNames[name] = routes[0]
+	for _, i := range routes {
+		engine.routes[i].Name = name
+	}
+}
+
+// URL builds the path of the route named name, params fill its :param and *catchAll
+// wildcards in order.
+//     router.GET("/user/:id/*action", handler).Name("user.action")
+//     engine.URL("user.action", 42, "edit") == "/user/42/edit"
+// It returns an error if the route does not exist or params do not match the wildcards.
+func (engine *Engine) URL(name string, params ...interface{}) (string, error) {
+	engine.pcLock.RLock()
+	i, ok := engine.routeNames[name]
+	var path string
+	if ok {
+		path = engine.routes[i].Path
+	}
+	engine.pcLock.RUnlock()
+	if !ok {
+		return "", errors.Errorf("[zouwu Engine]: route %q not found", name)
+	}
+	return buildURL(path, params)
+}
+
+// buildURL replaces the wildcards of the route path by params, escaping them.
+// Optional params missing from params are left out, params must match the param constraints.
+func buildURL(path string, params []interface{}) (string, error) {
+	var b strings.Builder
+	n := 0
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c != ':' && c != '*' {
+			b.WriteByte(c)
+			continue
+		}
+		end := i + 1
+		for end < len(path) && path[end] != '/' {
+			end++
+		}
+		wildcard := path[i:end]
+		optional := isOptionalSegment(wildcard)
+		name, constraint := splitConstraint(strings.TrimSuffix(wildcard, "?"), path)
+		if n >= len(params) {
+			if optional {
+				// optional params are the last segments, drop the slash before them
+				if p := strings.TrimSuffix(b.String(), "/"); p != "" {
+					return p, nil
+				}
+				return "/", nil
+			}
+			return "", errors.Errorf("[zouwu Engine]: missing param %q to build %q", name[1:], path)
+		}
+		value := cast.ToString(params[n])
+		n++
+		// the route would not match a value rejected by its constraint
+		if re := compileConstraint(constraint, path); re != nil && !re.MatchString(value) {
+			return "", errors.Errorf("[zouwu Engine]: param %q value %q does not match the constraint of %q", name[1:], value, path)
+		}
+		if c == ':' {
+			b.WriteString(url.PathEscape(value))
+		} else {
+			// the catch-all value keeps its slashes, the one before the wildcard is already written
+			segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+			for j := range segments {
+				segments[j] = url.PathEscape(segments[j])
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		}
+		i = end - 1
+	}
+	if n < len(params) {
+		return "", errors.Errorf("[zouwu Engine]: %d extra params to build %q", len(params)-n, path)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,186 @@
+package zouwu
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURL(t *testing.T) {
+	e := NewServer()
+	e.GET("/user/:id/*action", ok).Name("user.action")
+	e.GET("/files/*filepath", ok).Name("files")
+	api := e.Group("/api")
+	api.GET("/teams/:team", ok).Name("team")
+	e.GET("/about", ok)
+
+	tests := []struct {
+		name    string
+		params  []interface{}
+		want    string
+		wantErr bool
+	}{
+		{"user.action", []interface{}{42, "edit"}, "/user/42/edit", false},
+		{"user.action", []interface{}{"a b", "x/y z"}, "/user/a%20b/x/y%20z", false},
+		{"files", []interface{}{"/css/app.css"}, "/files/css/app.css", false},
+		{"team", []interface{}{"go/lang"}, "/api/teams/go%2Flang", false},
+		{"user.action", []interface{}{42}, "", true},
+		{"team", []interface{}{"go", "extra"}, "", true},
+		{"missing", nil, "", true},
+	}
+	for _, tt := range tests {
+		got, err := e.URL(tt.name, tt.params...)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("URL(%q, %v) = %q, %v, want %q, error %v", tt.name, tt.params, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	e.GET("/link", func(c *Context) error {
+		u, err := c.URLFor("team", "go")
+		if err != nil {
+			return err
+		}
+		return c.String(u)
+	})
+	if body := string(performRequest(e, newRequest(http.MethodGet, "/link")).Body()); body != "/api/teams/go" {
+		t.Errorf("URLFor() = %q, want %q", body, "/api/teams/go")
+	}
+}
+
+func TestNamePanics(t *testing.T) {
+	tests := map[string]func(e *Engine){
+		"no route":       func(e *Engine) { e.Name("none") },
+		"duplicate name": func(e *Engine) { e.GET("/a", ok).Name("a"); e.GET("/b", ok).Name("a") },
+	}
+	for name, register := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Name() did not panic", name)
+				}
+			}()
+			register(NewServer())
+		}()
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	e := NewServer()
+	auth := func(c *Context) error { return c.Next() }
+	e.GET("/user/:id/*action", ok).Name("ua")
+	e.POST("/user/:id/*action", ok).Name("ua2")
+	e.GET("/about", ok)
+	api := e.Group("/api", auth)
+	api.Any("/ping", ok).Name("ping")
+	e.Static("/assets", t.TempDir()).Name("assets")
+	e.SetMethodConfig("/about", &MethodConfig{Timeout: time.Second})
+
+	routes := e.Routes()
+	if len(routes) != 14 {
+		t.Fatalf("%d routes, want 14", len(routes))
+	}
+	names := make(map[string]string)
+	for _, route := range routes {
+		names[route.Method+" "+route.Path] = route.Name
+	}
+	tests := map[string]string{
+		"GET /user/:id/*action":  "ua",
+		"POST /user/:id/*action": "ua2",
+		"GET /about":             "",
+		"GET /api/ping":          "ping",
+		"TRACE /api/ping":        "ping",
+		"GET /assets/*filepath":  "assets",
+		"HEAD /assets/*filepath": "assets",
+	}
+	for key, want := range tests {
+		if got, ok := names[key]; !ok || got != want {
+			t.Errorf("name of %q = %q, want %q", key, got, want)
+		}
+	}
+
+	about, ping := routes[2], routes[3]
+	if about.Config == nil || about.Config.Timeout != time.Second {
+		t.Errorf("config of /about = %+v, want the MethodConfig", about.Config)
+	}
+	if !strings.HasSuffix(ping.Handler, ".ok") || ping.Middlewares != 1 || len(ping.Handlers) != 2 {
+		t.Errorf("route /api/ping = %+v, want handler ok after 1 middleware", ping)
+	}
+	if got, err := e.URL("assets", "css/app.css"); err != nil || got != "/assets/css/app.css" {
+		t.Errorf("URL(assets) = %q, %v", got, err)
+	}
+}
+
+// recordLogger records the messages logged at info level
+type recordLogger struct {
+	Logger
+	infos []string
+}
+
+func (l *recordLogger) Infof(format string, v ...interface{}) {
+	l.infos = append(l.infos, fmt.Sprintf(format, v...))
+}
+
+func TestLogRoutes(t *testing.T) {
+	e := NewServer()
+	logger := &recordLogger{Logger: NewFlogger(LogInfo)}
+	e.SetLogger(logger)
+	e.GET("/about", ok).Name("about")
+	// the table is printed when DebugMode is set directly, without SetDebugMode
+	e.DebugMode = true
+	e.setServer(e.newServer(), nil)
+
+	if len(logger.infos) != 2 {
+		t.Fatalf("logged %q, want the route count and one route", logger.infos)
+	}
+	if line := logger.infos[1]; !strings.Contains(line, "GET") || !strings.Contains(line, "/about") || !strings.Contains(line, "name=about") {
+		t.Errorf("route line = %q", line)
+	}
+}
+
+func TestURLConstraints(t *testing.T) {
+	e := NewServer()
+	e.GET("/users/:id<int>", ok).Name("user")
+	e.GET("/users/:name<alpha>", ok).Name("user.byname")
+	e.GET("/groups/:id<uint>/posts/:page?", ok).Name("gp")
+
+	tests := []struct {
+		name    string
+		params  []interface{}
+		want    string
+		wantErr bool
+	}{
+		{"user", []interface{}{42}, "/users/42", false},
+		{"user", []interface{}{"bob"}, "", true},
+		{"user.byname", []interface{}{"bob"}, "/users/bob", false},
+		{"user.byname", []interface{}{"bob1"}, "", true},
+		{"gp", []interface{}{7}, "/groups/7/posts", false},
+		{"gp", []interface{}{7, 2}, "/groups/7/posts/2", false},
+		{"gp", []interface{}{"abc"}, "", true},
+		{"gp", []interface{}{-7}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := e.URL(tt.name, tt.params...)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("URL(%q, %v) = %q, %v, want %q, error %v", tt.name, tt.params, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"/users/42":       "/users/:id<int>",
+		"/users/bob":      "/users/:name<alpha>",
+		"/users/bob1":     "",
+		"/groups/7/posts": "/groups/:id<uint>/posts/:page?",
+		"/groups/x/posts": "",
+	} {
+		resp := performRequest(e, newRequest(http.MethodGet, path))
+		if want == "" {
+			if resp.StatusCode() != http.StatusNotFound {
+				t.Errorf("GET %s: status = %d, want %d", path, resp.StatusCode(), http.StatusNotFound)
+			}
+		} else if body := string(resp.Body()); body != want {
+			t.Errorf("GET %s: routed to %q, want %q", path, body, want)
+		}
+	}
+}
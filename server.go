@@ -1,9 +1,15 @@
 package zouwu
 
 import (
+	"context"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
@@ -21,11 +27,22 @@ type HandlerFunc func(ctx *Context) error
 
 var defaultErrorHandler = func(ctx *Context, err error) {
 	switch e := err.(type) {
+	case *ValidationError:
+		if ctx.JSON(e) != nil {
+			ctx.Ctx.Response.Header.SetContentType(MIMETextPlainCharsetUTF8)
+			ctx.Ctx.Response.SetBodyString(e.Error())
+		}
+		ctx.Status(http.StatusBadRequest)
 	case *Error:
 		ctx.Ctx.Response.Header.SetContentType(MIMETextPlainCharsetUTF8)
 		ctx.Ctx.Response.SetBodyString(e.Error())
 		ctx.Status(e.Code)
 	default:
+		if id := ctx.RequestID(); id != "" {
+			ctx.Logger().Errorf("[zouwu Engine]: %s %s request_id=%s: %v", ctx.Ctx.Method(), ctx.Ctx.Path(), id, err)
+		} else {
+			ctx.Logger().Errorf("[zouwu Engine]: %s %s: %v", ctx.Ctx.Method(), ctx.Ctx.Path(), err)
+		}
 		ctx.Ctx.Response.Header.SetContentType(MIMETextPlainCharsetUTF8)
 		ctx.Ctx.Response.SetBodyString(e.Error())
 		ctx.Status(http.StatusInternalServerError)
@@ -34,11 +51,17 @@ var defaultErrorHandler = func(ctx *Context, err error) {
 
 // ServerConfig is the bm server config model
 type ServerConfig struct {
-	Network      string
-	Addr         string
+	Network string
+	Addr    string
+	// Timeout is the default deadline of the routes without MethodConfig.Timeout, zero disables it.
+	// The timeout is cooperative: handlers are not interrupted, they should watch Context.Done,
+	// and the route answers ErrRequestTimeout once it returns past its deadline.
 	Timeout      time.Duration
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// ShutdownTimeout bounds how long RunWithGracefulStop waits for
+	// in-flight requests to finish.
+	ShutdownTimeout time.Duration
 }
 
 // ErrHandler handler request raise err
@@ -52,12 +75,32 @@ type Engine struct {
 	conf *ServerConfig
 
 	pcLock        sync.RWMutex
-	methodConfigs map[string]*MethodConfig
-
-	trees  methodTrees
-	server *fasthttp.Server
-
-	// If enabled, the url.RawPath will be used to find parameters.
+	methodConfigs map[methodConfigKey]*MethodConfig
+
+	trees    methodTrees
+	server   *fasthttp.Server
+	listener net.Listener
+
+	// Enables automatic redirection if the current route can't be matched but a
+	// handler for the path with (without) the trailing slash exists.
+	// For example if /foo/ is requested but a route only exists for /foo, the
+	// client is redirected to /foo with http status code 301 for GET requests
+	// and 308 for all other request methods. Disabled by default.
+	RedirectTrailingSlash bool
+
+	// If enabled, the router tries to fix the current request path, if no
+	// handle is registered for it.
+	// First superfluous path elements like ../ or // are removed.
+	// Afterwards the router does a case-insensitive lookup of the cleaned path.
+	// If a handle can be found for this route, the router makes a redirection
+	// to the corrected path with status code 301 for GET requests and 308 for
+	// all other request methods.
+	// For example /FOO and /..//Foo could be redirected to /foo.
+	// RedirectTrailingSlash is independent of this option.
+	RedirectFixedPath bool
+
+	// If enabled, the original request path, before fasthttp decodes and normalizes it,
+	// will be used to find parameters. Encoded slashes (%2F) then stay inside one segment.
 	UseRawPath bool
 
 	// If true, the path value will be unescaped.
@@ -73,8 +116,20 @@ type Engine struct {
 	// handler.
 	HandleMethodNotAllowed bool
 
+	// If enabled, HEAD requests without a HEAD route are handled by the matching GET route.
+	// fasthttp omits the response body of HEAD requests but keeps Content-Length and the headers.
+	HandleHEAD bool
+
+	// If enabled, the router automatically replies to OPTIONS requests, including "OPTIONS *",
+	// with 204 and the Allow header listing the methods registered for the path.
+	// Routes registered for OPTIONS take priority over the automatic reply.
+	// The global middleware runs before the reply, e.g. to answer CORS preflight requests.
+	// Disabled by default.
+	HandleOPTIONS bool
+
 	allNoRoute  []HandlerFunc
 	allNoMethod []HandlerFunc
+	allOptions  []HandlerFunc
 	noRoute     []HandlerFunc
 	noMethod    []HandlerFunc
 	DebugMode   bool
@@ -84,6 +139,22 @@ type Engine struct {
 	errorHandler ErrHandler
 
 	logger Logger
+
+	hosts []*virtualHost
+	// routes and routeNames are guarded by pcLock
+	routes []RouteInfo
+	// routeNames maps the route names to the index of their route in routes
+	routeNames map[string]int
+
+	binders   map[string]Binder
+	validator *Validator
+	renderers *renderers
+	html      *htmlRender
+
+	// cookieKeys sign and encrypt the cookies, see SetCookieKeys
+	cookieKeys []cookieKey
+
+	shutdownHooks []func()
 }
 
 // NewServer returns a new blank Engine instance without any middleware attached.
@@ -97,8 +168,15 @@ func NewServer() *Engine {
 		},
 		conf:                   conf,
 		trees:                  make(methodTrees, 0, 9),
-		methodConfigs:          make(map[string]*MethodConfig),
+		methodConfigs:          make(map[methodConfigKey]*MethodConfig),
+		routeNames:             make(map[string]int),
+		binders:                defaultBinders(),
+		validator:              NewValidator(),
+		renderers:              defaultRenderers(),
 		HandleMethodNotAllowed: true,
+		HandleOPTIONS:          false,
+		RedirectTrailingSlash:  false,
+		RedirectFixedPath:      false,
 		DebugMode:              false,
 	}
 	if err := engine.SetConfig(conf); err != nil {
@@ -108,6 +186,8 @@ func NewServer() *Engine {
 		return engine.newContext()
 	}
 	engine.RouterGroup.engine = engine
+	engine.html = &htmlRender{engine: engine}
+	engine.renderers.register(MIMETextHTML, engine.html)
 	engine.NoRoute(func(c *Context) error {
 		c.Bytes(404, MIMETextHTML, default404Body)
 		c.Abort()
@@ -118,6 +198,7 @@ func NewServer() *Engine {
 		c.Abort()
 		return nil
 	})
+	engine.rebuildOptionsHandlers()
 	engine.logger = NewFlogger(LogInfo)
 	return engine
 }
@@ -125,20 +206,40 @@ func NewServer() *Engine {
 // defaultServerConfig return default server config
 func defaultServerConfig() *ServerConfig {
 	return &ServerConfig{
-		ReadTimeout:  1 * time.Second,
-		WriteTimeout: 1 * time.Second,
-		Timeout:      1 * time.Second,
-		Addr:         "127.0.0.1:8888",
+		ReadTimeout:     1 * time.Second,
+		WriteTimeout:    1 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+		Addr:            "127.0.0.1:8888",
 	}
 }
 
-// SetMethodConfig is used to set config on specified path
+// SetMethodConfig is used to set config on specified path, for every method of the routes
+// registered on the Engine. Use RouterGroup.SetMethodConfig to configure the routes of a Host.
 func (engine *Engine) SetMethodConfig(path string, mc *MethodConfig) {
+	engine.setMethodConfig(methodConfigKey{path: path}, mc)
+}
+
+// methodConfigKey identifies the routes a MethodConfig is set on, an empty method matches every method
+type methodConfigKey struct {
+	host   string
+	method string
+	path   string
+}
+
+func (engine *Engine) setMethodConfig(key methodConfigKey, mc *MethodConfig) {
 	engine.pcLock.Lock()
-	engine.methodConfigs[path] = mc
+	engine.methodConfigs[key] = mc
 	engine.pcLock.Unlock()
 }
 
+// methodConfig return the config of the route, pcLock must be held
+func (engine *Engine) methodConfig(host, method, path string) *MethodConfig {
+	if mc, ok := engine.methodConfigs[methodConfigKey{host, method, path}]; ok {
+		return mc
+	}
+	return engine.methodConfigs[methodConfigKey{host: host, path: path}]
+}
+
 // SetDebugMode  set debug mode will log engine info
 func (engine *Engine) SetDebugMode() {
 	engine.DebugMode = true
@@ -155,7 +256,7 @@ func (engine *Engine) SetLogger(logger Logger) {
 	engine.logger = logger
 }
 
-func (engine *Engine) addRoute(method, path string, handlers ...HandlerFunc) {
+func (engine *Engine) addRoute(host *virtualHost, method, path string, handlers ...HandlerFunc) {
 	if path[0] != '/' {
 		panic("[zouwu Engine]: path must begin with '/'")
 	}
@@ -165,48 +266,175 @@ func (engine *Engine) addRoute(method, path string, handlers ...HandlerFunc) {
 	if len(handlers) == 0 {
 		panic("[zouwu Engine]: there must be at least one handler")
 	}
-	root := engine.trees.get(method)
+	trees := &engine.trees
+	if host != nil {
+		trees = &host.trees
+	}
+	root := trees.get(method)
 	if root == nil {
 		root = new(node)
-		engine.trees = append(engine.trees, methodTree{method: method, root: root})
+		*trees = append(*trees, methodTree{method: method, root: root})
 	}
 
+	hostPattern := ""
+	if host != nil {
+		hostPattern = host.pattern
+	}
 	prelude := func(c *Context) error {
 		c.method = method
 		c.RoutePath = path
+		c.withTimeout(engine.methodTimeout(hostPattern, method, path))
 		return nil
 	}
+	// the route handler reports the timeout itself, so the middlewares see ErrRequestTimeout
+	// once Next returns, as for any other handler error
+	last := handlers[len(handlers)-1]
+	handle := func(c *Context) error {
+		err := last(c)
+		if c.Err() == context.DeadlineExceeded {
+			return ErrRequestTimeout
+		}
+		return err
+	}
 	engine.logger.Debugf("[zouwu engine]add method %s path: %s\n", method, path)
-	handlers = append([]HandlerFunc{prelude}, handlers...)
-	root.addRoute(path, handlers)
+	engine.recordRoute(host, method, path, handlers)
+	handlers = append(append([]HandlerFunc{prelude}, handlers[:len(handlers)-1]...), handle)
+	for _, p := range expandOptional(path) {
+		root.addRoute(p, handlers)
+	}
 }
 
 // MethodConfig is
 type MethodConfig struct {
+	// Timeout is the deadline of the route, see ServerConfig.Timeout.
 	Timeout time.Duration
 }
 
+// methodTimeout return the timeout configured on the route, fallback to ServerConfig.Timeout,
+// zero means the route has no deadline
+func (engine *Engine) methodTimeout(host, method, path string) time.Duration {
+	engine.pcLock.RLock()
+	mc := engine.methodConfig(host, method, path)
+	engine.pcLock.RUnlock()
+	if mc != nil && mc.Timeout > 0 {
+		return mc.Timeout
+	}
+	engine.lock.RLock()
+	timeout := engine.conf.Timeout
+	engine.lock.RUnlock()
+	return timeout
+}
+
 // Start listen and serve bm engine by given DSN.
 func (engine *Engine) Start() error {
 	conf := engine.conf
 	l, err := net.Listen(conf.Network, conf.Addr)
 	if err != nil {
-		panic(errors.Wrapf(err, "[zouwu Engine]: listen tcp: %s", conf.Addr))
+		return errors.Wrapf(err, "[zouwu Engine]: listen tcp: %s", conf.Addr)
+	}
+
+	engine.logger.Infof("[zouwu Engine]: start http listen addr: %s", l.Addr().String())
+	if err = engine.RunServer(engine.newServer(), l); err != nil {
+		return errors.Wrap(err, "[zouwu Engine]: serve")
+	}
+	engine.logger.Infof("[zouwu Engine]: server closed")
+	return nil
+}
+
+// RunWithGracefulStop listen and serve like Start, and shuts the server down gracefully
+// once one of the given signals is received.
+// SIGINT and SIGTERM are used when no signal is given.
+// In-flight requests are waited for at most ServerConfig.ShutdownTimeout.
+func (engine *Engine) RunWithGracefulStop(sig ...os.Signal) error {
+	conf := engine.conf
+	l, err := net.Listen(conf.Network, conf.Addr)
+	if err != nil {
+		return errors.Wrapf(err, "[zouwu Engine]: listen tcp: %s", conf.Addr)
 	}
 
 	engine.logger.Infof("[zouwu Engine]: start http listen addr: %s", l.Addr().String())
-	server := &fasthttp.Server{
+	// the server is registered before serving so a signal received right away
+	// still finds it in Shutdown
+	server := engine.newServer()
+	l = engine.setServer(server, l)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- engine.serve(server, l)
+	}()
+
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, sig...)
+	defer signal.Stop(quit)
+
+	select {
+	case err = <-serveErr:
+		return err
+	case s := <-quit:
+		engine.logger.Infof("[zouwu Engine]: receive signal %s, shutting down", s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
+	defer cancel()
+	if err = engine.Shutdown(ctx); err != nil {
+		return err
+	}
+	engine.logger.Infof("[zouwu Engine]: server closed")
+	return <-serveErr
+}
+
+// Shutdown gracefully shuts down the server: it stops accepting new connections,
+// waits for active requests to finish and then runs the OnShutdown hooks.
+// If ctx expires before all requests are done, the hooks are still run and ctx.Err() is returned.
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	engine.lock.RLock()
+	server, listener := engine.server, engine.listener
+	engine.lock.RUnlock()
+	defer engine.runShutdownHooks()
+	if server == nil {
+		return nil
+	}
+	// fasthttp only closes the listeners Serve has registered, a server shut down
+	// before it starts serving must not accept connections afterwards
+	listener.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Shutdown()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "[zouwu Engine]: shutdown")
+	}
+}
+
+// OnShutdown registers functions to be called by Shutdown once the server stopped.
+// Hooks are run in the order they were registered.
+func (engine *Engine) OnShutdown(hooks ...func()) {
+	engine.lock.Lock()
+	engine.shutdownHooks = append(engine.shutdownHooks, hooks...)
+	engine.lock.Unlock()
+}
+
+func (engine *Engine) runShutdownHooks() {
+	engine.lock.RLock()
+	hooks := engine.shutdownHooks
+	engine.lock.RUnlock()
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+func (engine *Engine) newServer() *fasthttp.Server {
+	conf := engine.conf
+	return &fasthttp.Server{
 		ReadTimeout:  time.Duration(conf.ReadTimeout),
 		WriteTimeout: time.Duration(conf.WriteTimeout),
 	}
-	if err := engine.RunServer(server, l); err != nil {
-		if errors.Cause(err) == http.ErrServerClosed {
-			engine.logger.Infof("[zouwu Engine]: server closed")
-			return nil
-		}
-		panic(errors.Wrapf(err, "[zouwu Engine]: engine.ListenServer(%+v, %+v)", server, l))
-	}
-	return nil
 }
 
 // AcquireCtx get context from pool and transform fasthttp.RequestCtx to zouwu.Context
@@ -232,43 +460,167 @@ func (engine *Engine) handler(rctx *fasthttp.RequestCtx) {
 }
 
 func (engine *Engine) prepareHandler(ctx *Context) {
+	trees, hostParams := engine.matchHost(ctx)
+	engine.route(ctx, trees)
+	if len(hostParams) > 0 {
+		ctx.Params = append(ctx.Params, hostParams...)
+	}
+}
+
+// route finds the handlers of the request in trees
+func (engine *Engine) route(ctx *Context, trees methodTrees) {
 	method := string(ctx.Ctx.Method())
 	rPath := string(ctx.Ctx.Request.URI().Path())
-	t := engine.trees
+	unescape := false
+	if engine.UseRawPath {
+		if rawPath := ctx.Ctx.Request.URI().PathOriginal(); len(rawPath) > 0 {
+			rPath = string(rawPath)
+			unescape = engine.UnescapePathValues
+		}
+	}
+	// the server-wide "OPTIONS *" request does not target any route
+	if string(ctx.Ctx.Request.Header.RequestURI()) == "*" {
+		rPath = "*"
+	}
+	if rPath != "*" {
+		if engine.handleTree(ctx, trees, method, rPath, unescape) {
+			return
+		}
+		if method == http.MethodHead && engine.HandleHEAD && engine.handleTree(ctx, trees, http.MethodGet, rPath, unescape) {
+			return
+		}
+	}
+
+	if method == http.MethodOptions && engine.HandleOPTIONS {
+		if allow := engine.allowed(trees, rPath, method, unescape); allow != "" {
+			ctx.Ctx.Response.Header.Set(HeaderAllow, allow)
+			ctx.handlers = engine.allOptions
+			return
+		}
+	} else if engine.HandleMethodNotAllowed {
+		if allow := engine.allowed(trees, rPath, method, unescape); allow != "" {
+			ctx.Ctx.Response.Header.Set(HeaderAllow, allow)
+			ctx.handlers = engine.allNoMethod
+			return
+		}
+	}
+	ctx.handlers = engine.allNoRoute
+}
+
+// handleTree looks rPath up in the tree of method, it reports whether the request
+// has been routed or redirected.
+func (engine *Engine) handleTree(ctx *Context, t methodTrees, method, rPath string, unescape bool) bool {
 	for i, tl := 0, len(t); i < tl; i++ {
 		if t[i].method != method {
 			continue
 		}
 		root := t[i].root
 		// Find route in tree
-		handlers, params, _ := root.getValue(rPath, ctx.Params, false)
+		handlers, params, tsr := root.getValue(rPath, ctx.Params, unescape)
 		if handlers != nil {
 			ctx.handlers = handlers
 			ctx.Params = params
-			return
+			return true
+		}
+		if method != http.MethodConnect && rPath != "/" {
+			if tsr && engine.RedirectTrailingSlash {
+				redirectTrailingSlash(ctx, rPath)
+				return true
+			}
+			if engine.RedirectFixedPath && redirectFixedPath(ctx, root, rPath, engine.RedirectTrailingSlash) {
+				return true
+			}
 		}
 		break
 	}
+	return false
+}
 
-	if engine.HandleMethodNotAllowed {
-		for _, tree := range engine.trees {
-			if tree.method == method {
+// allowed return the Allow header value for path, listing the methods having a route for it
+// other than reqMethod. For "*" all the registered methods are listed.
+func (engine *Engine) allowed(trees methodTrees, path, reqMethod string, unescape bool) string {
+	allowed := make([]string, 0, len(trees)+1)
+	hasOptions, hasGet, hasHead := false, false, false
+	for _, tree := range trees {
+		if path != "*" {
+			if tree.method == reqMethod {
 				continue
 			}
-			if handlers, _, _ := tree.root.getValue(rPath, nil, false); handlers != nil {
-				ctx.handlers = engine.allNoMethod
-				return
+			if handlers, _, _ := tree.root.getValue(path, nil, unescape); handlers == nil {
+				continue
 			}
 		}
+		allowed = append(allowed, tree.method)
+		hasOptions = hasOptions || tree.method == http.MethodOptions
+		hasGet = hasGet || tree.method == http.MethodGet
+		hasHead = hasHead || tree.method == http.MethodHead
 	}
-	ctx.handlers = engine.allNoRoute
+	if len(allowed) == 0 {
+		return ""
+	}
+	if engine.HandleHEAD && hasGet && !hasHead && reqMethod != http.MethodHead {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if engine.HandleOPTIONS && !hasOptions {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	sort.Strings(allowed)
+	return strings.Join(allowed, ", ")
+}
+
+func redirectTrailingSlash(ctx *Context, rPath string) {
+	if length := len(rPath); length > 1 && rPath[length-1] == '/' {
+		rPath = rPath[:length-1]
+	} else {
+		rPath += "/"
+	}
+	redirectRequest(ctx, rPath)
+}
+
+func redirectFixedPath(ctx *Context, root *node, rPath string, trailingSlash bool) bool {
+	if fixedPath, ok := root.findCaseInsensitivePath(cleanPath(rPath), trailingSlash); ok {
+		redirectRequest(ctx, string(fixedPath))
+		return true
+	}
+	return false
+}
+
+// redirectRequest redirects to location keeping the query string,
+// with 301 for GET requests and 308 for the others so the method and body are kept.
+func redirectRequest(ctx *Context, location string) {
+	code := http.StatusMovedPermanently
+	if !ctx.Ctx.IsGet() {
+		code = http.StatusPermanentRedirect
+	}
+	location = mountPrefix(ctx.Ctx) + location
+	if query := ctx.Ctx.URI().QueryString(); len(query) > 0 {
+		location += "?" + string(query)
+	}
+	ctx.Ctx.Response.Header.Set(HeaderLocation, location)
+	ctx.Status(code)
 }
 
 // RunServer will serve and start listening HTTP requests by given server and listener.
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
 func (engine *Engine) RunServer(server *fasthttp.Server, l net.Listener) (err error) {
+	return engine.serve(server, engine.setServer(server, l))
+}
+
+// setServer registers the server stopped by Shutdown, it return the listener to serve
+func (engine *Engine) setServer(server *fasthttp.Server, l net.Listener) net.Listener {
+	server.Handler = engine.handler
+	if engine.DebugMode {
+		engine.logRoutes()
+	}
+	listener := &closeOnceListener{Listener: l}
+	engine.lock.Lock()
 	engine.server = server
-	engine.server.Handler = engine.handler
+	engine.listener = listener
+	engine.lock.Unlock()
+	return listener
+}
+
+func (engine *Engine) serve(server *fasthttp.Server, l net.Listener) (err error) {
 	if err = server.Serve(l); err != nil {
 		err = errors.Wrapf(err, "listen server: %+v/%+v", server, l)
 		return
@@ -276,6 +628,21 @@ func (engine *Engine) RunServer(server *fasthttp.Server, l net.Listener) (err er
 	return
 }
 
+// closeOnceListener can be closed by both Shutdown and fasthttp, only the first Close
+// closes the listener
+type closeOnceListener struct {
+	net.Listener
+	once sync.Once
+	err  error
+}
+
+func (l *closeOnceListener) Close() error {
+	l.once.Do(func() {
+		l.err = l.Listener.Close()
+	})
+	return l.err
+}
+
 // Run will run server with address
 func (engine *Engine) Run(address string) error {
 	engine.conf.Addr = address
@@ -290,12 +657,15 @@ func (engine *Engine) newContext() *Context {
 // SetConfig is used to set the engine configuration.
 // Only the valid config will be loaded.
 func (engine *Engine) SetConfig(conf *ServerConfig) (err error) {
-	if conf.Timeout <= 0 {
-		return errors.New("[zouwu Engine]: config timeout must greater than 0")
+	if conf.Timeout < 0 {
+		return errors.New("[zouwu Engine]: config timeout must not be negative")
 	}
 	if conf.Network == "" {
 		conf.Network = "tcp"
 	}
+	if conf.ShutdownTimeout <= 0 {
+		conf.ShutdownTimeout = defaultServerConfig().ShutdownTimeout
+	}
 	engine.lock.Lock()
 	engine.conf = conf
 	engine.lock.Unlock()
@@ -322,6 +692,13 @@ func (engine *Engine) rebuild405Handlers() {
 	engine.allNoMethod = engine.combineHandlers(engine.noMethod)
 }
 
+func (engine *Engine) rebuildOptionsHandlers() {
+	engine.allOptions = engine.combineHandlers([]HandlerFunc{func(c *Context) error {
+		c.Status(http.StatusNoContent)
+		return nil
+	}})
+}
+
 // Use attaches a global middleware to the router. ie. the middleware attached though Use() will be
 // included in the handlers chain for every single request. Even 404, 405, static files...
 // For example, this is the right place for a logger or error management middleware.
@@ -329,9 +706,28 @@ func (engine *Engine) Use(middleware ...HandlerFunc) IRoutes {
 	engine.RouterGroup.Use(middleware...)
 	engine.rebuild404Handlers()
 	engine.rebuild405Handlers()
+	engine.rebuildOptionsHandlers()
 	return engine
 }
 
+// RegisterBinder registers the Binder used by Context.Bind for the given content type,
+// it replaces any Binder already registered for that type.
+func (engine *Engine) RegisterBinder(contentType string, b Binder) {
+	engine.binders[strings.ToLower(contentType)] = b
+}
+
+// RegisterRenderer registers the Renderer used by Context.Render for the given media type,
+// it replaces any Renderer already registered for that type.
+// Media types registered first are preferred when the client accepts several equally.
+func (engine *Engine) RegisterRenderer(mediaType string, r Renderer) {
+	engine.renderers.register(mediaType, r)
+}
+
+// Validator return the Validator used by Context.Bind, custom rules are registered on it.
+func (engine *Engine) Validator() *Validator {
+	return engine.validator
+}
+
 // SetErrHandler set customer ErrHandler
 func (engine *Engine) SetErrHandler(f ErrHandler) {
 	engine.errorHandler = f
@@ -0,0 +1,49 @@
+//go:build !windows
+// +build !windows
+
+package zouwu
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunWithGracefulStopEarlySignal(t *testing.T) {
+	// keep the signal from terminating the test binary before RunWithGracefulStop handles it
+	ignored := make(chan os.Signal, 16)
+	signal.Notify(ignored, syscall.SIGUSR1)
+	defer signal.Stop(ignored)
+
+	e := NewServer()
+	e.conf.Addr = "127.0.0.1:0"
+	hooks := make(chan struct{}, 1)
+	e.OnShutdown(func() { hooks <- struct{}{} })
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunWithGracefulStop(syscall.SIGUSR1)
+	}()
+
+	// the first signals usually arrive before the server starts serving
+	deadline := time.After(5 * time.Second)
+	for {
+		syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("RunWithGracefulStop() = %v", err)
+			}
+			select {
+			case <-hooks:
+			default:
+				t.Error("the OnShutdown hooks did not run")
+			}
+			return
+		case <-deadline:
+			t.Fatal("RunWithGracefulStop did not return after the signal")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package zouwu
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// newRequest builds a request for performRequest
+func newRequest(method, uri string) *fasthttp.Request {
+	req := new(fasthttp.Request)
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	return req
+}
+
+// performRequest runs req through the engine handler without a network round trip
+func performRequest(engine *Engine, req *fasthttp.Request) *fasthttp.Response {
+	rctx := new(fasthttp.RequestCtx)
+	rctx.Init(req, nil, nil)
+	engine.handler(rctx)
+	return &rctx.Response
+}
+
+// startServer serves engine on a random local port, it return the base URL and the
+// channel receiving the result of RunServer
+func startServer(t *testing.T, engine *Engine) (string, <-chan error) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- engine.RunServer(engine.newServer(), l)
+	}()
+	return "http://" + l.Addr().String(), serveErr
+}
+
+// events records the order of concurrent events
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	e.list = append(e.list, event)
+	e.mu.Unlock()
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.list...)
+}
+
+// getAsync sends a GET request without keep-alive, so Shutdown does not wait for an idle connection
+func getAsync(url string) <-chan string {
+	body := make(chan string, 1)
+	go func() {
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+		resp, err := client.Get(url)
+		if err != nil {
+			body <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+	return body
+}
+
+func TestShutdownDrainsRequests(t *testing.T) {
+	var log events
+	started := make(chan struct{})
+	e := NewServer()
+	e.GET("/slow", func(c *Context) error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		log.add("handler")
+		return c.String("done")
+	})
+	e.OnShutdown(func() { log.add("hook 1") }, func() { log.add("hook 2") })
+	e.OnShutdown(func() { log.add("hook 3") })
+	url, serveErr := startServer(t, e)
+
+	body := getAsync(url + "/slow")
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	if got := <-body; got != "done" {
+		t.Errorf("in-flight response = %q, want %q", got, "done")
+	}
+	want := []string{"handler", "hook 1", "hook 2", "hook 3"}
+	if got := log.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+	if err := <-serveErr; err != nil {
+		t.Errorf("RunServer() = %v", err)
+	}
+	if got := <-getAsync(url + "/slow"); !strings.HasPrefix(got, "error:") {
+		t.Errorf("response after shutdown = %q, want a connection error", got)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	var log events
+	started := make(chan struct{})
+	e := NewServer()
+	e.GET("/slow", func(c *Context) error {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		return c.String("done")
+	})
+	e.OnShutdown(func() { log.add("hook") })
+	url, _ := startServer(t, e)
+
+	body := getAsync(url + "/slow")
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := e.Shutdown(ctx); errors.Cause(err) != context.DeadlineExceeded {
+		t.Errorf("Shutdown() = %v, want the deadline error", err)
+	}
+	// the hooks run even when the requests did not finish in time
+	if got := log.get(); len(got) != 1 {
+		t.Errorf("events = %q, want the hook to run", got)
+	}
+	<-body
+}
+
+func TestShutdownBeforeServe(t *testing.T) {
+	e := NewServer()
+	hooks := 0
+	e.OnShutdown(func() { hooks++ })
+	if err := e.Shutdown(context.Background()); err != nil || hooks != 1 {
+		t.Errorf("Shutdown() = %v with %d hooks run, want nil and 1", err, hooks)
+	}
+
+	// a server registered but not serving yet stops as soon as it starts
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := e.newServer()
+	l = e.setServer(server, l)
+	if err = e.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- e.serve(server, l)
+	}()
+	select {
+	case err = <-serveErr:
+		if err != nil {
+			t.Errorf("serve() = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the server kept serving after Shutdown")
+	}
+}
+
+func TestStartListenError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	e := NewServer()
+	e.conf.Addr = l.Addr().String()
+	if err = e.Start(); err == nil {
+		t.Error("Start() on a used address = nil, want an error")
+	}
+}
+
+func ok(c *Context) error {
+	return c.String(c.RoutePath)
+}
+
+// expectResponse checks the status code and the Location header of a request
+type expectResponse struct {
+	method   string
+	path     string
+	code     int
+	location string
+}
+
+func checkResponses(t *testing.T, e *Engine, tests []expectResponse) {
+	t.Helper()
+	for _, tt := range tests {
+		resp := performRequest(e, newRequest(tt.method, tt.path))
+		if resp.StatusCode() != tt.code {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, resp.StatusCode(), tt.code)
+		}
+		if got := string(resp.Header.Peek(HeaderLocation)); got != tt.location {
+			t.Errorf("%s %s: Location = %q, want %q", tt.method, tt.path, got, tt.location)
+		}
+	}
+}
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	e := NewServer()
+	e.GET("/users/:id", ok)
+	e.POST("/users/:id", ok)
+	e.GET("/dir/", ok)
+	e.GET("/files/*filepath", ok)
+	e.GET("/teams/:team/members/", ok)
+
+	// disabled by default
+	checkResponses(t, e, []expectResponse{
+		{http.MethodGet, "/users/42/", http.StatusNotFound, ""},
+	})
+
+	e.RedirectTrailingSlash = true
+	checkResponses(t, e, []expectResponse{
+		{http.MethodGet, "/users/42/", http.StatusMovedPermanently, "/users/42"},
+		{http.MethodPost, "/users/42/", http.StatusPermanentRedirect, "/users/42"},
+		{http.MethodGet, "/users/42/?tab=1", http.StatusMovedPermanently, "/users/42?tab=1"},
+		{http.MethodGet, "/dir", http.StatusMovedPermanently, "/dir/"},
+		{http.MethodGet, "/files", http.StatusMovedPermanently, "/files/"},
+		{http.MethodGet, "/teams/go/members", http.StatusMovedPermanently, "/teams/go/members/"},
+		{http.MethodGet, "/users/42", http.StatusOK, ""},
+		{http.MethodGet, "/files/a/b/", http.StatusOK, ""},
+		{http.MethodGet, "/teams/go", http.StatusNotFound, ""},
+	})
+}
+
+func TestRedirectFixedPath(t *testing.T) {
+	e := NewServer()
+	e.RedirectFixedPath = true
+	e.GET("/users/:id", ok)
+	e.PUT("/users/:id", ok)
+	e.GET("/static/*filepath", ok)
+	e.GET("/about/", ok)
+
+	checkResponses(t, e, []expectResponse{
+		{http.MethodGet, "/USERS/Bob", http.StatusMovedPermanently, "/users/Bob"},
+		{http.MethodGet, "/Users/Bob?tab=posts&page=2", http.StatusMovedPermanently, "/users/Bob?tab=posts&page=2"},
+		{http.MethodPut, "/USERS/Bob?force=1", http.StatusPermanentRedirect, "/users/Bob?force=1"},
+		{http.MethodGet, "/STATIC/CSS/app.css", http.StatusMovedPermanently, "/static/CSS/app.css"},
+		{http.MethodGet, "/ABOUT", http.StatusNotFound, ""},
+		{http.MethodGet, "/contact", http.StatusNotFound, ""},
+	})
+
+	// the trailing slash is fixed too when RedirectTrailingSlash is enabled
+	e.RedirectTrailingSlash = true
+	checkResponses(t, e, []expectResponse{
+		{http.MethodGet, "/ABOUT?lang=fr", http.StatusMovedPermanently, "/about/?lang=fr"},
+	})
+}
+
+func TestRawPath(t *testing.T) {
+	param := func(c *Context) error {
+		return c.String(c.Params.ByName("name") + c.Params.ByName("filepath"))
+	}
+	tests := []struct {
+		useRawPath bool
+		unescape   bool
+		path       string
+		code       int
+		body       string
+	}{
+		{false, false, "/files/abc", http.StatusOK, "abc"},
+		{false, false, "/files/%E4%BD%A0", http.StatusOK, "你"},
+		{false, false, "/files/a%2Fb", http.StatusNotFound, ""},
+		{true, true, "/files/a%2Fb", http.StatusOK, "a/b"},
+		{true, true, "/files/%E4%BD%A0", http.StatusOK, "你"},
+		{true, true, "/files/a%20b", http.StatusOK, "a b"},
+		{true, false, "/files/a%2Fb", http.StatusOK, "a%2Fb"},
+		{true, false, "/files/%E4%BD%A0", http.StatusOK, "%E4%BD%A0"},
+		{true, true, "/files/a%2Fb/c", http.StatusNotFound, ""},
+		{true, true, "/static/a%2Fb/%E4%BD%A0", http.StatusOK, "/a/b/你"},
+		{true, false, "/static/a%2Fb/c", http.StatusOK, "/a%2Fb/c"},
+	}
+	for _, tt := range tests {
+		e := NewServer()
+		e.UseRawPath = tt.useRawPath
+		e.UnescapePathValues = tt.unescape
+		e.GET("/files/:name", param)
+		e.GET("/static/*filepath", param)
+		resp := performRequest(e, newRequest(http.MethodGet, tt.path))
+		if resp.StatusCode() != tt.code {
+			t.Errorf("UseRawPath %v, UnescapePathValues %v, GET %s: status = %d, want %d", tt.useRawPath, tt.unescape, tt.path, resp.StatusCode(), tt.code)
+		}
+		if body := string(resp.Body()); tt.code == http.StatusOK && body != tt.body {
+			t.Errorf("UseRawPath %v, UnescapePathValues %v, GET %s: body = %q, want %q", tt.useRawPath, tt.unescape, tt.path, body, tt.body)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	e := NewServer()
+	e.GET("/users/:id", ok)
+	e.PUT("/users/:id", ok)
+	e.DELETE("/users/:id", ok)
+	e.POST("/users", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		allow  string
+	}{
+		{http.MethodPost, "/users/1", http.StatusMethodNotAllowed, "DELETE, GET, PUT"},
+		{http.MethodGet, "/users", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodGet, "/users/1", http.StatusOK, ""},
+		{http.MethodGet, "/teams", http.StatusNotFound, ""},
+		// OPTIONS is not answered automatically by default
+		{http.MethodOptions, "/users/1", http.StatusMethodNotAllowed, "DELETE, GET, PUT"},
+	}
+	for _, tt := range tests {
+		resp := performRequest(e, newRequest(tt.method, tt.path))
+		if resp.StatusCode() != tt.code {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.path, resp.StatusCode(), tt.code)
+		}
+		if got := string(resp.Header.Peek(HeaderAllow)); got != tt.allow {
+			t.Errorf("%s %s: Allow = %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+	}
+
+	e.HandleMethodNotAllowed = false
+	if resp := performRequest(e, newRequest(http.MethodPost, "/users/1")); resp.StatusCode() != http.StatusNotFound {
+		t.Errorf("status without HandleMethodNotAllowed = %d, want %d", resp.StatusCode(), http.StatusNotFound)
+	}
+}
+
+func TestHandleOPTIONS(t *testing.T) {
+	e := NewServer()
+	e.HandleOPTIONS = true
+	var middleware int
+	e.Use(func(c *Context) error {
+		middleware++
+		return c.Next()
+	})
+	e.GET("/users/:id", ok)
+	e.PUT("/users/:id", ok)
+	e.GET("/teams", ok)
+	e.OPTIONS("/teams", func(c *Context) error {
+		return c.String("custom")
+	})
+
+	tests := []struct {
+		path  string
+		code  int
+		allow string
+		body  string
+	}{
+		{"/users/1", http.StatusNoContent, "GET, OPTIONS, PUT", ""},
+		{"*", http.StatusNoContent, "GET, OPTIONS, PUT", ""},
+		// a route registered for OPTIONS takes precedence
+		{"/teams", http.StatusOK, "", "custom"},
+		{"/missing", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		middleware = 0
+		resp := performRequest(e, newRequest(http.MethodOptions, tt.path))
+		if resp.StatusCode() != tt.code {
+			t.Errorf("OPTIONS %s: status = %d, want %d", tt.path, resp.StatusCode(), tt.code)
+		}
+		if got := string(resp.Header.Peek(HeaderAllow)); got != tt.allow {
+			t.Errorf("OPTIONS %s: Allow = %q, want %q", tt.path, got, tt.allow)
+		}
+		if tt.body != "" && string(resp.Body()) != tt.body {
+			t.Errorf("OPTIONS %s: body = %q, want %q", tt.path, resp.Body(), tt.body)
+		}
+		if middleware != 1 {
+			t.Errorf("OPTIONS %s: middleware ran %d times, want 1", tt.path, middleware)
+		}
+	}
+
+	// OPTIONS is listed by the 405 Allow header
+	if got := string(performRequest(e, newRequest(http.MethodPost, "/users/1")).Header.Peek(HeaderAllow)); got != "GET, OPTIONS, PUT" {
+		t.Errorf("405 Allow = %q, want %q", got, "GET, OPTIONS, PUT")
+	}
+}
+
+func TestHandleHEAD(t *testing.T) {
+	e := NewServer()
+	e.HandleHEAD = true
+	e.GET("/users/:id", func(c *Context) error {
+		c.Ctx.Response.Header.Set("X-Route", "GET")
+		return c.String("user body")
+	})
+	e.GET("/teams", ok)
+	e.HEAD("/teams", func(c *Context) error {
+		c.Ctx.Response.Header.Set("X-Route", "HEAD")
+		return nil
+	})
+	url, _ := startServer(t, e)
+	defer e.Shutdown(context.Background())
+
+	tests := []struct {
+		path          string
+		route         string
+		contentLength int64
+	}{
+		{"/users/1", "GET", int64(len("user body"))},
+		// an explicit HEAD route takes precedence
+		{"/teams", "HEAD", 0},
+	}
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	for _, tt := range tests {
+		resp, err := client.Head(url + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || len(body) != 0 {
+			t.Errorf("HEAD %s = %d %q, want 200 without body", tt.path, resp.StatusCode, body)
+		}
+		if got := resp.Header.Get("X-Route"); got != tt.route {
+			t.Errorf("HEAD %s: routed to %q, want %q", tt.path, got, tt.route)
+		}
+		if tt.contentLength > 0 && resp.ContentLength != tt.contentLength {
+			t.Errorf("HEAD %s: Content-Length = %d, want %d", tt.path, resp.ContentLength, tt.contentLength)
+		}
+	}
+
+	e.HandleHEAD = false
+	resp := performRequest(e, newRequest(http.MethodHead, "/users/1"))
+	if resp.StatusCode() != http.StatusMethodNotAllowed || string(resp.Header.Peek(HeaderAllow)) != "GET" {
+		t.Errorf("HEAD without HandleHEAD = %d with Allow %q, want 405 with Allow GET", resp.StatusCode(), resp.Header.Peek(HeaderAllow))
+	}
+}
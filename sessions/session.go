@@ -0,0 +1,311 @@
+// Package sessions provides server-side sessions for zouwu.
+//     router.Use(sessions.Sessions(sessions.Config{IdleTimeout: 15 * time.Minute}))
+//     router.POST("/login", func(c *zouwu.Context) error {
+//         s := sessions.Get(c)
+//         s.Regenerate() // new ID on privilege change
+//         s.Set("user", userID)
+//         s.Flash("notice", "welcome back")
+//         return c.String("ok")
+//     })
+// The session values are encoded with encoding/gob: register the custom types
+// stored in sessions with gob.Register.
+package sessions
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"net/http"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/pkg/errors"
+)
+
+// SessionKey is the key of Context.Keys holding the *Session of the request.
+const SessionKey = "zouwu.session"
+
+// idLength is the number of random bytes of the session IDs
+const idLength = 32
+
+var defaultCookieOptions = zouwu.CookieOptions{
+	Path:     "/",
+	HttpOnly: true,
+	SameSite: http.SameSiteLaxMode,
+}
+
+// Config defines the config of the Sessions middleware.
+type Config struct {
+	// Store defaults to a MemoryStore.
+	Store Store
+	// CookieName is the name of the cookie holding the session ID, defaults to "session_id".
+	CookieName string
+	// Cookie are the attributes of the session cookie, defaults to HttpOnly and SameSite Lax.
+	// The cookie has no Max-Age unless set, so it is dropped when the browser closes.
+	Cookie zouwu.CookieOptions
+	// IdleTimeout expires the sessions not used for that long, defaults to 30 minutes.
+	IdleTimeout time.Duration
+	// AbsoluteTimeout expires the sessions that long after they were created whatever
+	// their activity, defaults to 24 hours.
+	AbsoluteTimeout time.Duration
+	// TouchInterval is how often the unchanged sessions are saved to push back their
+	// idle expiry, defaults to one minute.
+	TouchInterval time.Duration
+}
+
+// record is the encoded form of a session
+type record struct {
+	ID       string
+	Values   map[string]interface{}
+	Flashes  map[string][]interface{}
+	Created  time.Time
+	Accessed time.Time
+}
+
+// Session is the session of one request, it is not safe for concurrent use.
+type Session struct {
+	record record
+	ctx    *zouwu.Context
+	conf   *Config
+	// isNew is true until the session is saved for the first time
+	isNew   bool
+	changed bool
+	// staleID is the ID to delete from the store once the session is saved
+	staleID string
+	// cleared is set by Destroy, the cookie is removed unless the session is used again
+	cleared bool
+}
+
+// Sessions returns a middleware that loads the session of the request before the handlers
+// and saves it after them, see Get. It panics if the config is invalid.
+func Sessions(config ...Config) zouwu.HandlerFunc {
+	var conf Config
+	if len(config) > 0 {
+		conf = config[0]
+	}
+	if conf.Store == nil {
+		conf.Store = NewMemoryStore()
+	}
+	if conf.CookieName == "" {
+		conf.CookieName = "session_id"
+	}
+	if conf.Cookie == (zouwu.CookieOptions{}) {
+		conf.Cookie = defaultCookieOptions
+	}
+	if conf.IdleTimeout <= 0 {
+		conf.IdleTimeout = 30 * time.Minute
+	}
+	if conf.AbsoluteTimeout <= 0 {
+		conf.AbsoluteTimeout = 24 * time.Hour
+	}
+	if conf.TouchInterval <= 0 {
+		conf.TouchInterval = time.Minute
+	}
+	if conf.TouchInterval > conf.IdleTimeout {
+		panic("[zouwu sessions]: TouchInterval must not exceed IdleTimeout")
+	}
+
+	return func(ctx *zouwu.Context) error {
+		s, err := load(ctx, &conf)
+		if err != nil {
+			return err
+		}
+		ctx.Set(SessionKey, s)
+		ctx.Next()
+		return s.save()
+	}
+}
+
+// Get returns the session of the request, nil when the Sessions middleware is not used.
+func Get(ctx *zouwu.Context) *Session {
+	value, _ := ctx.Get(SessionKey)
+	s, _ := value.(*Session)
+	return s
+}
+
+// load returns the session of the request cookie, or a new session when it has none
+// or the session expired
+func load(ctx *zouwu.Context, conf *Config) (*Session, error) {
+	now := time.Now()
+	id := ctx.Cookie(conf.CookieName)
+	if validID(id) {
+		data, err := conf.Store.Load(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "[zouwu sessions]: load session")
+		}
+		if data != nil {
+			var r record
+			if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&r); err == nil && r.ID == id {
+				s := &Session{record: r, ctx: ctx, conf: conf}
+				if !s.expired(now) {
+					return s, nil
+				}
+			}
+			// expired or unreadable sessions are dropped
+			if err = conf.Store.Delete(ctx, id); err != nil {
+				return nil, errors.Wrap(err, "[zouwu sessions]: delete session")
+			}
+		}
+	}
+	return newSession(ctx, conf, now)
+}
+
+func newSession(ctx *zouwu.Context, conf *Config, now time.Time) (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		record: record{ID: id, Created: now, Accessed: now},
+		ctx:    ctx,
+		conf:   conf,
+		isNew:  true,
+	}, nil
+}
+
+func (s *Session) expired(now time.Time) bool {
+	return now.Sub(s.record.Accessed) >= s.conf.IdleTimeout || now.Sub(s.record.Created) >= s.conf.AbsoluteTimeout
+}
+
+// save writes the session to the store and sets the cookie when needed
+func (s *Session) save() error {
+	ctx, conf := s.ctx, s.conf
+	if s.staleID != "" {
+		if err := conf.Store.Delete(ctx, s.staleID); err != nil {
+			return errors.Wrap(err, "[zouwu sessions]: delete session")
+		}
+		s.staleID = ""
+	}
+	if !s.changed {
+		if s.cleared {
+			ctx.ClearCookie(conf.CookieName, conf.Cookie)
+			return nil
+		}
+		// new sessions are only stored once they hold values
+		if s.isNew || time.Since(s.record.Accessed) < conf.TouchInterval {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	s.record.Accessed = now
+	ttl := conf.IdleTimeout
+	if remaining := s.record.Created.Add(conf.AbsoluteTimeout).Sub(now); remaining < ttl {
+		ttl = remaining
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&s.record); err != nil {
+		return errors.Wrap(err, "[zouwu sessions]: encode session")
+	}
+	if err := conf.Store.Save(ctx, s.record.ID, buf.Bytes(), ttl); err != nil {
+		return errors.Wrap(err, "[zouwu sessions]: save session")
+	}
+	if s.isNew {
+		ctx.SetCookie(conf.CookieName, s.record.ID, conf.Cookie)
+	}
+	s.isNew, s.changed, s.cleared = false, false, false
+	return nil
+}
+
+// ID returns the session ID.
+func (s *Session) ID() string {
+	return s.record.ID
+}
+
+// IsNew reports whether the session was created by this request.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Get returns the value of key, nil when it is not set.
+func (s *Session) Get(key string) interface{} {
+	return s.record.Values[key]
+}
+
+// Set sets the value of key.
+func (s *Session) Set(key string, value interface{}) {
+	if s.record.Values == nil {
+		s.record.Values = make(map[string]interface{})
+	}
+	s.record.Values[key] = value
+	s.changed = true
+}
+
+// Delete removes key.
+func (s *Session) Delete(key string) {
+	if _, ok := s.record.Values[key]; ok {
+		delete(s.record.Values, key)
+		s.changed = true
+	}
+}
+
+// Flash adds a message read once by Flashes, usually in the next request.
+func (s *Session) Flash(key string, value interface{}) {
+	if s.record.Flashes == nil {
+		s.record.Flashes = make(map[string][]interface{})
+	}
+	s.record.Flashes[key] = append(s.record.Flashes[key], value)
+	s.changed = true
+}
+
+// Flashes returns and removes the flash messages of key.
+func (s *Session) Flashes(key string) []interface{} {
+	messages, ok := s.record.Flashes[key]
+	if ok {
+		delete(s.record.Flashes, key)
+		s.changed = true
+	}
+	return messages
+}
+
+// Regenerate gives the session a new ID and keeps its values, call it when the privileges
+// change (login, logout, role change) to prevent session fixation.
+func (s *Session) Regenerate() error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	if !s.isNew && s.staleID == "" {
+		s.staleID = s.record.ID
+	}
+	s.record.ID = id
+	s.isNew, s.changed = true, true
+	return nil
+}
+
+// Destroy deletes the session from the store and removes its cookie,
+// values set afterwards start a new session.
+func (s *Session) Destroy() error {
+	if !s.isNew {
+		if err := s.conf.Store.Delete(s.ctx, s.record.ID); err != nil {
+			return errors.Wrap(err, "[zouwu sessions]: delete session")
+		}
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	s.record = record{ID: id, Created: now, Accessed: now}
+	s.staleID = ""
+	s.isNew, s.changed, s.cleared = true, false, true
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, idLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "[zouwu sessions]: generate session ID")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// validID rejects the cookies that can not be session IDs before they reach the store
+func validID(id string) bool {
+	if len(id) != base64.RawURLEncoding.EncodedLen(idLength) {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,252 @@
+package sessions_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/DCRcoder/zouwu/sessions"
+	"github.com/DCRcoder/zouwu/zouwutest"
+)
+
+// browser keeps the cookies set by the responses and sends them with the next requests
+type browser struct {
+	client  *zouwutest.Client
+	cookies map[string]string
+}
+
+func newBrowser(client *zouwutest.Client) *browser {
+	return &browser{client: client, cookies: make(map[string]string)}
+}
+
+func (b *browser) GET(path string) *zouwutest.Response {
+	req := b.client.GET(path)
+	for name, value := range b.cookies {
+		req.WithCookie(name, value)
+	}
+	resp := req.Expect()
+	for _, cookie := range resp.Raw().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(b.cookies, cookie.Name)
+		} else {
+			b.cookies[cookie.Name] = cookie.Value
+		}
+	}
+	return resp
+}
+
+var sessionKey = bytes.Repeat([]byte("k"), 32)
+
+// sessionRoutes are the GET routes of the session tests
+var sessionRoutes = map[string]zouwu.HandlerFunc{
+	"/set": func(c *zouwu.Context) error {
+		s := sessions.Get(c)
+		s.Set(c.Query("key"), c.Query("value"))
+		return c.String(s.ID())
+	},
+	"/big": func(c *zouwu.Context) error {
+		sessions.Get(c).Set("big", strings.Repeat("x", 5000))
+		return c.String("big")
+	},
+	"/get": func(c *zouwu.Context) error {
+		return c.String(fmt.Sprint(sessions.Get(c).Get(c.Query("key"))))
+	},
+	"/delete": func(c *zouwu.Context) error {
+		sessions.Get(c).Delete(c.Query("key"))
+		return c.String("deleted")
+	},
+	"/flash": func(c *zouwu.Context) error {
+		sessions.Get(c).Flash("notice", c.Query("message"))
+		return c.String("flashed")
+	},
+	"/flashes": func(c *zouwu.Context) error {
+		return c.String(fmt.Sprint(sessions.Get(c).Flashes("notice")))
+	},
+	"/login": func(c *zouwu.Context) error {
+		s := sessions.Get(c)
+		if err := s.Regenerate(); err != nil {
+			return err
+		}
+		s.Set("user", c.Query("user"))
+		return c.String(s.ID())
+	},
+	"/logout": func(c *zouwu.Context) error {
+		return sessions.Get(c).Destroy()
+	},
+	"/id": func(c *zouwu.Context) error {
+		s := sessions.Get(c)
+		return c.String(fmt.Sprint(s.ID(), " ", s.IsNew()))
+	},
+}
+
+func TestSessionValues(t *testing.T) {
+	e := zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	b := newBrowser(zouwutest.New(t, e))
+
+	// sessions are only stored once they hold values
+	b.GET("/get?key=a").Status(http.StatusOK).Body("<nil>").NoHeader(zouwu.HeaderSetCookie)
+	resp := b.GET("/set?key=a&value=1").Status(http.StatusOK).
+		HeaderContains(zouwu.HeaderSetCookie, "HttpOnly").
+		HeaderContains(zouwu.HeaderSetCookie, "SameSite=Lax")
+	id := string(resp.Bytes())
+	if b.cookies["session_id"] != id {
+		t.Fatalf("cookie = %q, want the session ID %q", b.cookies["session_id"], id)
+	}
+	b.GET("/id").Body(id + " false")
+
+	// the cookie is set once, later changes only update the store
+	b.GET("/set?key=b&value=2").Body(id).NoHeader(zouwu.HeaderSetCookie)
+	b.GET("/get?key=a").Body("1")
+	b.GET("/get?key=b").Body("2")
+	b.GET("/delete?key=a")
+	b.GET("/get?key=a").Body("<nil>")
+	b.GET("/get?key=b").Body("2")
+
+	// unknown and malformed IDs start a new session
+	e = zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	other := newBrowser(zouwutest.New(t, e))
+	other.cookies["session_id"] = id
+	other.GET("/get?key=b").Body("<nil>")
+	other.cookies["session_id"] = "../../etc/passwd"
+	other.GET("/id").BodyContains(" true")
+}
+
+func TestSessionFlashes(t *testing.T) {
+	e := zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	b := newBrowser(zouwutest.New(t, e))
+	b.GET("/flash?message=saved")
+	b.GET("/flash?message=sent")
+	b.GET("/flashes").Body("[saved sent]")
+	b.GET("/flashes").Body("[]")
+}
+
+func TestSessionRegenerate(t *testing.T) {
+	e := zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	b := newBrowser(zouwutest.New(t, e))
+	before := string(b.GET("/set?key=cart&value=3").Bytes())
+	after := string(b.GET("/login?user=bob").HeaderContains(zouwu.HeaderSetCookie, "session_id=").Bytes())
+	if after == before || b.cookies["session_id"] != after {
+		t.Fatalf("session ID %q -> %q, cookie %q, want a new ID", before, after, b.cookies["session_id"])
+	}
+	b.GET("/get?key=user").Body("bob")
+	b.GET("/get?key=cart").Body("3")
+
+	// the previous ID is no longer valid
+	b.cookies["session_id"] = before
+	b.GET("/get?key=cart").Body("<nil>")
+}
+
+func TestSessionDestroy(t *testing.T) {
+	e := zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	b := newBrowser(zouwutest.New(t, e))
+	id := string(b.GET("/login?user=bob").Bytes())
+	b.GET("/logout").HeaderContains(zouwu.HeaderSetCookie, "session_id=;")
+	if _, ok := b.cookies["session_id"]; ok {
+		t.Fatalf("cookies = %v, want the session cookie removed", b.cookies)
+	}
+	b.cookies["session_id"] = id
+	b.GET("/get?key=user").Body("<nil>")
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	e := zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{IdleTimeout: 80 * time.Millisecond, TouchInterval: 10 * time.Millisecond}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	b := newBrowser(zouwutest.New(t, e))
+	b.GET("/set?key=a&value=1")
+	// reads push back the idle expiry
+	for i := 0; i < 4; i++ {
+		time.Sleep(30 * time.Millisecond)
+		b.GET("/get?key=a").Body("1")
+	}
+	time.Sleep(100 * time.Millisecond)
+	b.GET("/get?key=a").Body("<nil>")
+
+	e = zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{
+		IdleTimeout:     time.Second,
+		AbsoluteTimeout: 80 * time.Millisecond,
+		TouchInterval:   10 * time.Millisecond,
+	}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	b = newBrowser(zouwutest.New(t, e))
+	b.GET("/set?key=a&value=1")
+	b.GET("/get?key=a").Body("1")
+	time.Sleep(100 * time.Millisecond)
+	b.GET("/get?key=a").Body("<nil>")
+}
+
+func TestCookieStore(t *testing.T) {
+	e := zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{Store: sessions.NewCookieStore("session_data")}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	b := newBrowser(zouwutest.New(t, e))
+	b.GET("/set?key=a&value=1").HeaderContains(zouwu.HeaderSetCookie, "session_data=")
+	b.GET("/get?key=a").Body("1")
+
+	// the whole session lives in the cookie
+	e = zouwu.NewServer()
+	e.SetCookieKeys(sessionKey)
+	e.Use(sessions.Sessions(sessions.Config{Store: sessions.NewCookieStore("session_data")}))
+	for path, handler := range sessionRoutes {
+		e.GET(path, handler)
+	}
+	restarted := newBrowser(zouwutest.New(t, e))
+	restarted.cookies = b.cookies
+	restarted.GET("/get?key=a").Body("1")
+
+	b.GET("/logout")
+	if len(b.cookies) != 0 {
+		t.Errorf("cookies = %v, want them removed", b.cookies)
+	}
+
+	// sessions too large for a cookie fail
+	b.GET("/big").Status(http.StatusInternalServerError).NoHeader(zouwu.HeaderSetCookie)
+}
+
+func TestSessionsInvalidConfig(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic when TouchInterval exceeds IdleTimeout")
+		}
+	}()
+	sessions.Sessions(sessions.Config{IdleTimeout: time.Second, TouchInterval: time.Minute})
+}
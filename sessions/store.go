@@ -0,0 +1,130 @@
+package sessions
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+	"github.com/pkg/errors"
+)
+
+// Store persists the encoded sessions by ID. Implement it over Redis, a database...
+// to share the sessions between instances, ctx carries the deadline of the request.
+type Store interface {
+	// Load returns the session id, nil when it does not exist or expired.
+	Load(ctx *zouwu.Context, id string) ([]byte, error)
+	// Save stores the session id, it may be dropped once ttl elapsed.
+	Save(ctx *zouwu.Context, id string, data []byte, ttl time.Duration) error
+	// Delete removes the session id.
+	Delete(ctx *zouwu.Context, id string) error
+}
+
+// memorySweepInterval is the number of saves between two sweeps of the expired sessions
+const memorySweepInterval = 1024
+
+// MemoryStore keeps the sessions in the memory of the process.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	saves    int
+}
+
+type memorySession struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]memorySession)}
+}
+
+// Load implements Store.
+func (s *MemoryStore) Load(_ *zouwu.Context, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok || !time.Now().Before(session.expires) {
+		return nil, nil
+	}
+	return session.data, nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(_ *zouwu.Context, id string, data []byte, ttl time.Duration) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = memorySession{data: append([]byte(nil), data...), expires: now.Add(ttl)}
+	s.saves++
+	if s.saves >= memorySweepInterval {
+		s.saves = 0
+		for key, session := range s.sessions {
+			if !now.Before(session.expires) {
+				delete(s.sessions, key)
+			}
+		}
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(_ *zouwu.Context, id string) error {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// maxCookieSize is the size browsers are guaranteed to keep for one cookie
+const maxCookieSize = 4096
+
+// CookieStore keeps the whole session in an encrypted cookie of the client, so no state
+// is kept on the server. It requires the keys of zouwu.Engine.SetCookieKeys and sessions
+// small enough to fit in a cookie (about 3KB once encoded). Destroy and Regenerate can not
+// revoke the copies of the cookie kept by the client, they stay valid until they expire.
+type CookieStore struct {
+	name    string
+	options zouwu.CookieOptions
+}
+
+// NewCookieStore returns a CookieStore writing the sessions in the cookie name,
+// options defaults to HttpOnly and SameSite Lax.
+func NewCookieStore(name string, options ...zouwu.CookieOptions) *CookieStore {
+	s := &CookieStore{name: name}
+	if len(options) > 0 {
+		s.options = options[0]
+	} else {
+		s.options = defaultCookieOptions
+	}
+	return s
+}
+
+// Load implements Store.
+func (s *CookieStore) Load(ctx *zouwu.Context, _ string) ([]byte, error) {
+	data, ok := ctx.EncryptedCookie(s.name)
+	if !ok {
+		return nil, nil
+	}
+	return []byte(data), nil
+}
+
+// Save implements Store.
+func (s *CookieStore) Save(ctx *zouwu.Context, _ string, data []byte, ttl time.Duration) error {
+	opts := s.options
+	opts.MaxAge = int((ttl + time.Second - 1) / time.Second)
+	if err := ctx.SetEncryptedCookie(s.name, string(data), opts); err != nil {
+		return err
+	}
+	if cookie := ctx.Ctx.Response.Header.PeekCookie(s.name); len(cookie) > maxCookieSize {
+		ctx.Ctx.Response.Header.DelCookie(s.name)
+		return errors.Errorf("[zouwu sessions]: session of %d bytes does not fit in a cookie", len(data))
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *CookieStore) Delete(ctx *zouwu.Context, _ string) error {
+	ctx.ClearCookie(s.name, s.options)
+	return nil
+}
@@ -0,0 +1,342 @@
+package zouwu
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// StaticConfig configures how RouterGroup.Static, StaticFS and StaticFile serve files.
+type StaticConfig struct {
+	// Index lists the files served for a directory, defaults to index.html.
+	Index []string
+	// Browse enables directory listing when no index file exists.
+	Browse bool
+	// ByteRange enables Range requests.
+	ByteRange bool
+	// Compress serves the precompressed name.br or name.gz sidecar of a file
+	// when it exists and the client accepts the encoding.
+	Compress bool
+	// MaxAge sets the Cache-Control max-age of the served files, zero omits the header.
+	MaxAge time.Duration
+}
+
+var defaultStaticConfig = StaticConfig{
+	Index: []string{"index.html"},
+}
+
+func staticConfig(config []StaticConfig) StaticConfig {
+	if len(config) == 0 {
+		return defaultStaticConfig
+	}
+	return config[0]
+}
+
+// Static serves the files of the root directory under relativePath.
+//     router.Static("/assets", "./public", zouwu.StaticConfig{ByteRange: true, MaxAge: time.Hour})
+func (group *RouterGroup) Static(relativePath, root string, config ...StaticConfig) IRoutes {
+	return group.StaticFS(relativePath, os.DirFS(root), config...)
+}
+
+// StaticFS serves the files of fsys under relativePath, e.g. an embed.FS.
+func (group *RouterGroup) StaticFS(relativePath string, fsys fs.FS, config ...StaticConfig) IRoutes {
+	if strings.ContainsAny(relativePath, ":*") {
+		panic("URL parameters can not be used when serving a static folder")
+	}
+	conf := staticConfig(config)
+	handler := func(c *Context) error {
+		return serveFS(c, fsys, c.Params.ByName("filepath"), conf)
+	}
+	urlPattern := path.Join(relativePath, "/*filepath")
+	return group.handleMethods([]string{"GET", "HEAD"}, urlPattern, handler)
+}
+
+// StaticFile serves the single file filePath under relativePath.
+func (group *RouterGroup) StaticFile(relativePath, filePath string, config ...StaticConfig) IRoutes {
+	if strings.ContainsAny(relativePath, ":*") {
+		panic("URL parameters can not be used when serving a static file")
+	}
+	conf := staticConfig(config)
+	fsys, name := os.DirFS(filepath.Dir(filePath)), filepath.Base(filePath)
+	handler := func(c *Context) error {
+		return serveFile(c, fsys, name, conf)
+	}
+	return group.handleMethods([]string{"GET", "HEAD"}, relativePath, handler)
+}
+
+// serveFS serves the file or directory at urlPath of fsys
+func serveFS(c *Context, fsys fs.FS, urlPath string, conf StaticConfig) error {
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return ErrNotFound
+	}
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return ErrNotFound
+	}
+	if !info.IsDir() {
+		return serveFile(c, fsys, name, conf)
+	}
+
+	// directories are served with a trailing slash so relative links resolve
+	reqPath := c.Ctx.Path()
+	if len(reqPath) == 0 || reqPath[len(reqPath)-1] != '/' {
+		redirectRequest(c, string(reqPath)+"/")
+		return nil
+	}
+	for _, index := range conf.Index {
+		indexName := path.Join(name, index)
+		if info, err := fs.Stat(fsys, indexName); err == nil && !info.IsDir() {
+			return serveFile(c, fsys, indexName, conf)
+		}
+	}
+	if !conf.Browse {
+		return ErrForbidden
+	}
+	return serveDir(c, fsys, name)
+}
+
+// serveDir writes the listing of directory name
+func serveDir(c *Context, fsys fs.FS, name string) error {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return ErrNotFound
+	}
+	title := html.EscapeString(string(c.Ctx.Path()))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<html><head><title>%s</title></head><body><h1>%s</h1><ul>", title, title)
+	if name != "." {
+		buf.WriteString(`<li><a href="../">../</a></li>`)
+	}
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+		link := url.URL{Path: entryName}
+		fmt.Fprintf(&buf, `<li><a href="%s">%s</a></li>`, html.EscapeString(link.String()), html.EscapeString(entryName))
+	}
+	buf.WriteString("</ul></body></html>")
+	c.Bytes(http.StatusOK, MIMETextHTMLCharsetUTF8, buf.Bytes())
+	return nil
+}
+
+// serveFile writes the file name of fsys, honouring conditional and range requests
+func serveFile(c *Context, fsys fs.FS, name string, conf StaticConfig) error {
+	header := &c.Ctx.Response.Header
+	f, info, encoding, err := openFile(c, fsys, name, conf.Compress)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	// the content type is the one of the uncompressed file, never of its sidecar
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "" && encoding == "" {
+		ctype = sniffContentType(f)
+	} else if ctype == "" {
+		ctype = sniffFile(fsys, name)
+	}
+	etag, err := fileETag(f, info)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	header.Set(HeaderETag, etag)
+	modTime := info.ModTime()
+	if !modTime.IsZero() {
+		header.Set(HeaderLastModified, modTime.UTC().Format(http.TimeFormat))
+	}
+	if conf.MaxAge > 0 {
+		header.Set(HeaderCacheControl, "public, max-age="+strconv.Itoa(int(conf.MaxAge.Seconds())))
+	}
+	if conf.Compress {
+		header.Add(HeaderVary, HeaderAcceptEncoding)
+	}
+	if encoding != "" {
+		header.Set(HeaderContentEncoding, encoding)
+	}
+	if notModified(c, etag, modTime) {
+		f.Close()
+		c.Status(http.StatusNotModified)
+		return nil
+	}
+	header.SetContentType(ctype)
+
+	size := int(info.Size())
+	seeker, seekable := f.(io.Seeker)
+	if !conf.ByteRange || !seekable {
+		c.Ctx.SetBodyStream(f, size)
+		return nil
+	}
+	header.Set(HeaderAcceptRanges, "bytes")
+	byteRange := c.Ctx.Request.Header.Peek(HeaderRange)
+	if len(byteRange) == 0 || !ifRange(c, etag, modTime) {
+		c.Ctx.SetBodyStream(f, size)
+		return nil
+	}
+	start, end, err := fasthttp.ParseByteRange(byteRange, size)
+	if err != nil {
+		f.Close()
+		header.Set(HeaderContentRange, "bytes */"+strconv.Itoa(size))
+		c.Status(http.StatusRequestedRangeNotSatisfiable)
+		return nil
+	}
+	if _, err = seeker.Seek(int64(start), io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	header.Set(HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	c.Ctx.SetBodyStream(&limitedFile{Reader: io.LimitReader(f, int64(end-start+1)), Closer: f}, end-start+1)
+	c.Status(http.StatusPartialContent)
+	return nil
+}
+
+// openFile opens name, or its precompressed sidecar accepted by the client when compress is true
+func openFile(c *Context, fsys fs.FS, name string, compress bool) (fs.File, fs.FileInfo, string, error) {
+	if compress {
+		// sidecars the client does not accept are skipped, from the preferred encoding down
+		offers := []string{"br", "gzip"}
+		for len(offers) > 0 {
+			encoding := c.NegotiateEncoding(offers...)
+			if encoding == "" {
+				break
+			}
+			if f, info, err := openRegular(fsys, name+sidecarExt[encoding]); err == nil {
+				return f, info, encoding, nil
+			}
+			offers = removeString(offers, encoding)
+		}
+	}
+	f, info, err := openRegular(fsys, name)
+	return f, info, "", err
+}
+
+// sidecarExt maps the content codings to the extension of the precompressed files
+var sidecarExt = map[string]string{"br": ".br", "gzip": ".gz"}
+
+func removeString(list []string, s string) []string {
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func openRegular(fsys fs.FS, name string) (fs.File, fs.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, nil, fs.ErrNotExist
+	}
+	return f, info, nil
+}
+
+// sniffContentType detects the content type from the first 512 bytes of seekable files
+func sniffContentType(f fs.File) string {
+	seeker, ok := f.(io.Seeker)
+	if !ok {
+		return MIMEOctetStream
+	}
+	var buf [512]byte
+	n, _ := io.ReadFull(f, buf[:])
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return MIMEOctetStream
+	}
+	return http.DetectContentType(buf[:n])
+}
+
+// sniffFile detects the content type of the file name
+func sniffFile(fsys fs.FS, name string) string {
+	f, _, err := openRegular(fsys, name)
+	if err != nil {
+		return MIMEOctetStream
+	}
+	defer f.Close()
+	return sniffContentType(f)
+}
+
+// fileETag return a weak ETag built from the file size and modification time,
+// or from the file content when the modification time is unknown (e.g. embed.FS).
+func fileETag(f fs.File, info fs.FileInfo) (string, error) {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano()), nil
+	}
+	seeker, ok := f.(io.Seeker)
+	if !ok {
+		return fmt.Sprintf(`W/"%x"`, info.Size()), nil
+	}
+	h := crc32.NewIEEE()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`W/"%x-%x"`, info.Size(), h.Sum32()), nil
+}
+
+// notModified checks If-None-Match, then If-Modified-Since
+func notModified(c *Context, etag string, modTime time.Time) bool {
+	reqHeader := &c.Ctx.Request.Header
+	if inm := reqHeader.Peek(HeaderIfNoneMatch); len(inm) > 0 {
+		return etagMatch(string(inm), etag)
+	}
+	if modTime.IsZero() {
+		return false
+	}
+	ims, err := http.ParseTime(string(reqHeader.Peek(HeaderIfModifiedSince)))
+	return err == nil && !modTime.Truncate(time.Second).After(ims)
+}
+
+// ifRange reports whether the Range header applies according to If-Range
+func ifRange(c *Context, etag string, modTime time.Time) bool {
+	ir := string(c.Ctx.Request.Header.Peek(HeaderIfRange))
+	if ir == "" {
+		return true
+	}
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, "W/") {
+		// weak ETags never match for If-Range
+		return !strings.HasPrefix(etag, "W/") && ir == etag
+	}
+	t, err := http.ParseTime(ir)
+	return err == nil && !modTime.IsZero() && modTime.Truncate(time.Second).Equal(t)
+}
+
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedFile closes the underlying file once the range has been sent
+type limitedFile struct {
+	io.Reader
+	io.Closer
+}
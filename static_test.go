@@ -0,0 +1,152 @@
+package zouwu
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.css":        "body{}",
+		"sub/index.html": "index",
+		"list/a.txt":     "a",
+	})
+	e := NewServer()
+	e.Static("/s", dir, StaticConfig{Index: []string{"index.html"}, Browse: true, ByteRange: true})
+	e.StaticFile("/favicon.css", filepath.Join(dir, "app.css"))
+
+	tests := []struct {
+		method string
+		uri    string
+		header map[string]string
+		code   int
+		body   string
+		check  map[string]string
+	}{
+		{"GET", "/s/app.css", nil, http.StatusOK, "body{}", map[string]string{"Content-Type": "text/css; charset=utf-8"}},
+		{"HEAD", "/s/app.css", nil, http.StatusOK, "", map[string]string{"Content-Length": "6"}},
+		{"GET", "/favicon.css", nil, http.StatusOK, "body{}", nil},
+		{"GET", "/s/app.css", map[string]string{"Range": "bytes=1-2"}, http.StatusPartialContent, "od", map[string]string{"Content-Range": "bytes 1-2/6"}},
+		{"GET", "/s/app.css", map[string]string{"Range": "bytes=9-"}, http.StatusRequestedRangeNotSatisfiable, "", map[string]string{"Content-Range": "bytes */6"}},
+		{"GET", "/s/sub?v=1", nil, http.StatusMovedPermanently, "", map[string]string{"Location": "/s/sub/?v=1"}},
+		{"GET", "/s/sub/", nil, http.StatusOK, "index", nil},
+		{"GET", "/s/list/", nil, http.StatusOK, `<li><a href="a.txt">a.txt</a></li>`, nil},
+		{"GET", "/s/missing.css", nil, http.StatusNotFound, "", nil},
+		{"GET", "/s/../static_test.go", nil, http.StatusNotFound, "", nil},
+	}
+	for _, tt := range tests {
+		req := newRequest(tt.method, tt.uri)
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		resp := performRequest(e, req)
+		if resp.StatusCode() != tt.code {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.uri, resp.StatusCode(), tt.code)
+		}
+		if body := string(resp.Body()); tt.body != "" && !strings.Contains(body, tt.body) {
+			t.Errorf("%s %s: body = %q, want %q", tt.method, tt.uri, body, tt.body)
+		}
+		for k, v := range tt.check {
+			if got := string(resp.Header.Peek(k)); got != v {
+				t.Errorf("%s %s: %s = %q, want %q", tt.method, tt.uri, k, got, v)
+			}
+		}
+	}
+}
+
+func TestStaticConditional(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"app.js": "js"})
+	e := NewServer()
+	e.Static("/s", dir, StaticConfig{MaxAge: time.Hour})
+
+	resp := performRequest(e, newRequest(http.MethodGet, "/s/app.js"))
+	etag, lastModified := string(resp.Header.Peek(HeaderETag)), string(resp.Header.Peek(HeaderLastModified))
+	if etag == "" || lastModified == "" {
+		t.Fatalf("ETag = %q, Last-Modified = %q, want both", etag, lastModified)
+	}
+	if cc := string(resp.Header.Peek(HeaderCacheControl)); cc != "public, max-age=3600" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	for name, value := range map[string]string{HeaderIfNoneMatch: etag, HeaderIfModifiedSince: lastModified} {
+		req := newRequest(http.MethodGet, "/s/app.js")
+		req.Header.Set(name, value)
+		if resp := performRequest(e, req); resp.StatusCode() != http.StatusNotModified {
+			t.Errorf("%s: status = %d, want %d", name, resp.StatusCode(), http.StatusNotModified)
+		}
+	}
+}
+
+func TestStaticSidecar(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.js":    "plain",
+		"app.js.gz": "gzipped",
+		"app.js.br": "brotli",
+		"lib.js":    "plain",
+		"lib.js.gz": "gzipped",
+		"README":    "<html><body>readme</body></html>",
+		"README.gz": "\x1f\x8b\x08gzipped",
+	})
+	e := NewServer()
+	e.Static("/s", dir, StaticConfig{Compress: true})
+
+	tests := []struct {
+		path           string
+		acceptEncoding string
+		encoding       string
+		body           string
+		contentType    string
+	}{
+		{"/s/app.js", "", "", "plain", "text/javascript; charset=utf-8"},
+		{"/s/app.js", "gzip, br", "br", "brotli", "text/javascript; charset=utf-8"},
+		{"/s/app.js", "gzip;q=1, br;q=0.5", "gzip", "gzipped", "text/javascript; charset=utf-8"},
+		{"/s/app.js", "gzip;q=0", "", "plain", "text/javascript; charset=utf-8"},
+		{"/s/app.js", "br;q=0, *", "gzip", "gzipped", "text/javascript; charset=utf-8"},
+		{"/s/lib.js", "br, gzip;q=0.5", "gzip", "gzipped", "text/javascript; charset=utf-8"},
+		// files without a known extension are sniffed from the uncompressed content
+		{"/s/README", "gzip", "gzip", "\x1f\x8b\x08gzipped", "text/html; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		req := newRequest(http.MethodGet, tt.path)
+		req.Header.Set(HeaderAcceptEncoding, tt.acceptEncoding)
+		resp := performRequest(e, req)
+		if body := string(resp.Body()); resp.StatusCode() != http.StatusOK || body != tt.body {
+			t.Errorf("GET %s with Accept-Encoding %q = %d %q, want 200 %q", tt.path, tt.acceptEncoding, resp.StatusCode(), body, tt.body)
+		}
+		if got := string(resp.Header.Peek(HeaderContentEncoding)); got != tt.encoding {
+			t.Errorf("GET %s with Accept-Encoding %q: Content-Encoding = %q, want %q", tt.path, tt.acceptEncoding, got, tt.encoding)
+		}
+		if got := string(resp.Header.ContentType()); got != tt.contentType {
+			t.Errorf("GET %s with Accept-Encoding %q: Content-Type = %q, want %q", tt.path, tt.acceptEncoding, got, tt.contentType)
+		}
+	}
+}
+
+func TestStaticFSWildcardPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Static() with a parameter did not panic")
+		}
+	}()
+	NewServer().Static("/s/:name", ".")
+}
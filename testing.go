@@ -0,0 +1,59 @@
+package zouwu
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// defaultTestTimeout is the timeout of Engine.Test when none is given
+const defaultTestTimeout = time.Second
+
+// Test sends req to the engine through an in-memory listener and return the response,
+// no socket is opened so routes and middleware can be unit tested:
+//     req := httptest.NewRequest("GET", "/hello", nil)
+//     resp, err := engine.Test(req)
+// The response body is read in full before Test returns.
+// timeout defaults to one second, a timeout <= 0 waits for the response without limit.
+func (engine *Engine) Test(req *http.Request, timeout ...time.Duration) (*http.Response, error) {
+	deadline := defaultTestTimeout
+	if len(timeout) > 0 {
+		deadline = timeout[0]
+	}
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	server := engine.newServer()
+	server.Handler = engine.handler
+	go server.Serve(ln)
+
+	conn, err := ln.Dial()
+	if err != nil {
+		return nil, errors.Wrap(err, "[zouwu Engine]: dial in-memory listener")
+	}
+	defer conn.Close()
+	if deadline > 0 {
+		if err = conn.SetDeadline(time.Now().Add(deadline)); err != nil {
+			return nil, errors.Wrap(err, "[zouwu Engine]: set test deadline")
+		}
+	}
+	if err = req.Write(conn); err != nil {
+		return nil, errors.Wrap(err, "[zouwu Engine]: write test request")
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return nil, errors.Wrap(err, "[zouwu Engine]: read test response")
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "[zouwu Engine]: read test response body")
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,68 @@
+package zouwu
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEngineTest(t *testing.T) {
+	e := NewServer()
+	e.POST("/echo/:name", func(c *Context) error {
+		c.Ctx.Response.Header.Set("X-Name", c.URLParam("name"))
+		c.Status(http.StatusCreated)
+		return c.String(c.Query("q") + " " + string(c.GetRequestBody()))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/echo/bob?q=hi", strings.NewReader("body"))
+	resp, err := e.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if got := resp.Header.Get("X-Name"); got != "bob" {
+		t.Errorf("X-Name = %q, want %q", got, "bob")
+	}
+	if string(body) != "hi body" {
+		t.Errorf("body = %q, want %q", body, "hi body")
+	}
+
+	resp, err = e.Test(httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestEngineTestTimeout(t *testing.T) {
+	e := NewServer()
+	e.GET("/slow", func(c *Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return c.String("done")
+	})
+
+	if _, err := e.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), 20*time.Millisecond); err == nil {
+		t.Error("Test did not time out")
+	}
+	for _, timeout := range []time.Duration{time.Second, 0, -1} {
+		resp, err := e.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), timeout)
+		if err != nil {
+			t.Errorf("Test with timeout %v: %v", timeout, err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Test with timeout %v: status = %d, want %d", timeout, resp.StatusCode, http.StatusOK)
+		}
+	}
+}
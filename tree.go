@@ -6,6 +6,7 @@ package zouwu
 
 import (
 	"net/url"
+	"regexp"
 	"strings"
 	"unicode"
 )
@@ -65,6 +66,13 @@ func min(a, b int) int {
 func countParams(path string) uint8 {
 	var n uint
 	for i := 0; i < len(path); i++ {
+		if path[i] == '<' {
+			// skip the param constraint, it ends the segment
+			for i < len(path) && path[i] != '/' {
+				i++
+			}
+			continue
+		}
 		if path[i] != ':' && path[i] != '*' {
 			continue
 		}
@@ -94,6 +102,9 @@ type node struct {
 	nType     nodeType
 	maxParams uint8
 	wildChild bool
+	// key and constraint of param nodes
+	key        string
+	constraint *regexp.Regexp
 }
 
 // increments priority of the given child and reorders if necessary.
@@ -176,23 +187,26 @@ func (n *node) addRoute(path string, handlers []HandlerFunc) {
 				path = path[i:]
 
 				if n.wildChild {
-					n = n.children[0]
-					n.priority++
+					// Check if the wildcard matches
+					if child := n.wildChildFor(path); child != nil {
+						n = child
+						n.priority++
 
-					// Update maxParams of the child node
-					if numParams > n.maxParams {
-						n.maxParams = numParams
+						// Update maxParams of the child node
+						if numParams > n.maxParams {
+							n.maxParams = numParams
+						}
+						numParams--
+						continue walk
 					}
-					numParams--
 
-					// Check if the wildcard matches
-					if len(path) >= len(n.path) && n.path == path[:len(n.path)] {
-						// check for longer wildcard, e.g. :name and :names
-						if len(n.path) >= len(path) || path[len(n.path)] == '/' {
-							continue walk
-						}
+					// params with different constraints are siblings
+					if path[0] == ':' && n.children[0].nType == param {
+						n.insertParamSibling(numParams, path, fullPath, handlers)
+						return
 					}
 
+					n = n.children[0]
 					pathSeg := path
 					if n.nType != catchAll {
 						pathSeg = strings.SplitN(path, "/", 2)[0]
@@ -251,6 +265,48 @@ func (n *node) addRoute(path string, handlers []HandlerFunc) {
 	}
 }
 
+// wildChildFor return the wildcard child of n registered with the same wildcard as path
+func (n *node) wildChildFor(path string) *node {
+	for _, child := range n.children {
+		// check for longer wildcard, e.g. :name and :names
+		if len(path) >= len(child.path) && child.path == path[:len(child.path)] &&
+			(len(child.path) >= len(path) || path[len(child.path)] == '/') {
+			return child
+		}
+	}
+	return nil
+}
+
+// insertParamSibling adds the param starting path beside the param children of n,
+// e.g. :name<alpha> beside :id<int>. The siblings must have different constraints,
+// the unconstrained one is tried last.
+func (n *node) insertParamSibling(numParams uint8, path string, fullPath string, handlers []HandlerFunc) {
+	end := strings.IndexByte(path, '/')
+	if end < 0 {
+		end = len(path)
+	}
+	_, constraint := splitConstraint(path[:end], fullPath)
+	for _, child := range n.children {
+		if _, existing := splitConstraint(child.path, fullPath); existing == constraint {
+			panic("'" + path[:end] +
+				"' in new path '" + fullPath +
+				"' conflicts with existing wildcard '" + child.path +
+				"', params of the same segment must have different constraints")
+		}
+	}
+
+	// the param is inserted under an empty node, then moved beside the existing ones
+	holder := &node{}
+	holder.insertChild(numParams, path, fullPath, handlers)
+	child := holder.children[0]
+	last := len(n.children) - 1
+	if child.constraint != nil && n.children[last].constraint == nil {
+		n.children = append(n.children[:last], child, n.children[last])
+	} else {
+		n.children = append(n.children, child)
+	}
+}
+
 func (n *node) insertChild(numParams uint8, path string, fullPath string, handlers []HandlerFunc) {
 	var offset int // already handled bytes of the path
 
@@ -264,14 +320,13 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 		// find wildcard end (either '/' or path end)
 		end := i + 1
 		for end < max && path[end] != '/' {
-			switch path[end] {
-			// the wildcard name must not contain ':' and '*'
-			case ':', '*':
-				panic("only one wildcard per path segment is allowed, has: '" +
-					path[i:] + "' in path '" + fullPath + "'")
-			default:
-				end++
-			}
+			end++
+		}
+		name, constraint := splitConstraint(path[i:end], fullPath)
+		// the wildcard name must not contain ':' and '*'
+		if strings.ContainsAny(name[1:], ":*") {
+			panic("only one wildcard per path segment is allowed, has: '" +
+				path[i:] + "' in path '" + fullPath + "'")
 		}
 
 		// check if this Node existing children which would be
@@ -282,7 +337,7 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 		}
 
 		// check if the wildcard has a name
-		if end-i < 2 {
+		if len(name) < 2 {
 			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
 		}
 
@@ -294,8 +349,10 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 			}
 
 			child := &node{
-				nType:     param,
-				maxParams: numParams,
+				nType:      param,
+				maxParams:  numParams,
+				key:        name[1:],
+				constraint: compileConstraint(constraint, fullPath),
 			}
 			n.children = []*node{child}
 			n.wildChild = true
@@ -316,6 +373,8 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 				n.children = []*node{child}
 				n = child
 			}
+			// continue after the wildcard, its constraint may contain ':' and '*'
+			i = end
 
 		} else { // catchAll
 			if end != max || numParams > 1 {
@@ -396,58 +455,20 @@ walk: // Outer loop for walking the tree
 				}
 
 				// handle wildcard child
-				n = n.children[0]
-				switch n.nType {
+				switch n.children[0].nType {
 				case param:
-					// find param end (either '/' or path end)
-					end := 0
-					for end < len(path) && path[end] != '/' {
-						end++
-					}
-
-					// save param value
-					if cap(p) < int(n.maxParams) {
-						p = make(Params, 0, n.maxParams)
-					}
-					i := len(p)
-					p = p[:i+1] // expand slice within preallocated capacity
-					p[i].Key = n.path[1:]
-					val := path[:end]
-					if unescape {
-						var err error
-						if p[i].Value, err = url.QueryUnescape(val); err != nil {
-							p[i].Value = val // fallback, in case of error
-						}
-					} else {
-						p[i].Value = val
-					}
-
-					// we need to go deeper!
-					if end < len(path) {
-						if len(n.children) > 0 {
-							path = path[end:]
-							n = n.children[0]
-							continue walk
+					// the params are tried in order until one matches the value and the rest of the path
+					for _, child := range n.children {
+						childHandlers, childParams, childTSR := child.getParamValue(path, p, unescape)
+						if childHandlers != nil {
+							return childHandlers, childParams, false
 						}
-
-						// ... but we can't
-						tsr = len(path) == end+1
-						return
-					}
-
-					if handlers = n.handlers; handlers != nil {
-						return
+						tsr = tsr || childTSR
 					}
-					if len(n.children) == 1 {
-						// No handle found. Check if a handle for this path + a
-						// trailing slash exists for TSR recommendation
-						n = n.children[0]
-						tsr = n.path == "/" && n.handlers != nil
-					}
-
-					return
+					return nil, p, tsr
 
 				case catchAll:
+					n = n.children[0]
 					// save param value
 					if cap(p) < int(n.maxParams) {
 						p = make(Params, 0, n.maxParams)
@@ -457,7 +478,7 @@ walk: // Outer loop for walking the tree
 					p[i].Key = n.path[2:]
 					if unescape {
 						var err error
-						if p[i].Value, err = url.QueryUnescape(path); err != nil {
+						if p[i].Value, err = url.PathUnescape(path); err != nil {
 							p[i].Value = path // fallback, in case of error
 						}
 					} else {
@@ -506,6 +527,60 @@ walk: // Outer loop for walking the tree
 	}
 }
 
+// getParamValue matches the param node n against the first segment of path,
+// then looks the rest of the path up in its children.
+func (n *node) getParamValue(path string, p Params, unescape bool) (handlers []HandlerFunc, _ Params, tsr bool) {
+	// find param end (either '/' or path end)
+	end := 0
+	for end < len(path) && path[end] != '/' {
+		end++
+	}
+
+	// save param value
+	if cap(p) < len(p)+int(n.maxParams) {
+		grown := make(Params, len(p), len(p)+int(n.maxParams))
+		copy(grown, p)
+		p = grown
+	}
+	i := len(p)
+	p = p[:i+1] // expand slice within preallocated capacity
+	p[i].Key = n.key
+	val := path[:end]
+	if unescape {
+		var err error
+		if p[i].Value, err = url.PathUnescape(val); err != nil {
+			p[i].Value = val // fallback, in case of error
+		}
+	} else {
+		p[i].Value = val
+	}
+	// a value not matching the constraint is left to the next param
+	if n.constraint != nil && !n.constraint.MatchString(p[i].Value) {
+		return nil, p[:i], false
+	}
+
+	// we need to go deeper!
+	if end < len(path) {
+		if len(n.children) > 0 {
+			return n.children[0].getValue(path[end:], p, unescape)
+		}
+
+		// ... but we can't
+		return nil, p, len(path) == end+1
+	}
+
+	if handlers = n.handlers; handlers != nil {
+		return handlers, p, false
+	}
+	if len(n.children) == 1 {
+		// No handle found. Check if a handle for this path + a
+		// trailing slash exists for TSR recommendation
+		child := n.children[0]
+		tsr = child.path == "/" && child.handlers != nil
+	}
+	return nil, p, tsr
+}
+
 // findCaseInsensitivePath makes a case-insensitive lookup of the given path and tries to find a handler.
 // It can optionally also fix trailing slashes.
 // It returns the case-corrected path and a bool indicating whether the lookup
@@ -541,41 +616,11 @@ func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (ciPa
 				return
 			}
 
-			n = n.children[0]
-			switch n.nType {
+			switch n.children[0].nType {
 			case param:
-				// find param end (either '/' or path end)
-				k := 0
-				for k < len(path) && path[k] != '/' {
-					k++
-				}
-
-				// add param value to case insensitive path
-				ciPath = append(ciPath, path[:k]...)
-
-				// we need to go deeper!
-				if k < len(path) {
-					if len(n.children) > 0 {
-						path = path[k:]
-						n = n.children[0]
-						continue
-					}
-
-					// ... but we can't
-					if fixTrailingSlash && len(path) == k+1 {
-						return ciPath, true
-					}
-					return
-				}
-
-				if n.handlers != nil {
-					return ciPath, true
-				} else if fixTrailingSlash && len(n.children) == 1 {
-					// No handle found. Check if a handle for this path + a
-					// trailing slash exists
-					n = n.children[0]
-					if n.path == "/" && n.handlers != nil {
-						return append(ciPath, '/'), true
+				for _, child := range n.children {
+					if out, found := child.findCaseInsensitiveParam(path, fixTrailingSlash); found {
+						return append(ciPath, out...), true
 					}
 				}
 				return
@@ -625,3 +670,48 @@ func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (ciPa
 	}
 	return
 }
+
+// findCaseInsensitiveParam matches the param node n against the first segment of path,
+// then makes a case-insensitive lookup of the rest of the path in its children.
+func (n *node) findCaseInsensitiveParam(path string, fixTrailingSlash bool) (ciPath []byte, found bool) {
+	// find param end (either '/' or path end)
+	k := 0
+	for k < len(path) && path[k] != '/' {
+		k++
+	}
+
+	if n.constraint != nil && !n.constraint.MatchString(path[:k]) {
+		return
+	}
+
+	// add param value to case insensitive path
+	ciPath = append(ciPath, path[:k]...)
+
+	// we need to go deeper!
+	if k < len(path) {
+		if len(n.children) > 0 {
+			if out, found := n.children[0].findCaseInsensitivePath(path[k:], fixTrailingSlash); found {
+				return append(ciPath, out...), true
+			}
+			return nil, false
+		}
+
+		// ... but we can't
+		if fixTrailingSlash && len(path) == k+1 {
+			return ciPath, true
+		}
+		return nil, false
+	}
+
+	if n.handlers != nil {
+		return ciPath, true
+	} else if fixTrailingSlash && len(n.children) == 1 {
+		// No handle found. Check if a handle for this path + a
+		// trailing slash exists
+		child := n.children[0]
+		if child.path == "/" && child.handlers != nil {
+			return append(ciPath, '/'), true
+		}
+	}
+	return nil, false
+}
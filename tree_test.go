@@ -0,0 +1,147 @@
+package zouwu
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// routeHandlers return handlers whose error names the route, to tell which route matched
+func routeHandlers(route string) []HandlerFunc {
+	return []HandlerFunc{func(*Context) error { return errors.New(route) }}
+}
+
+func newTestTree(routes ...string) *node {
+	tree := new(node)
+	for _, route := range routes {
+		tree.addRoute(route, routeHandlers(route))
+	}
+	return tree
+}
+
+type testRequest struct {
+	path   string
+	route  string
+	params Params
+	tsr    bool
+}
+
+func checkRequests(t *testing.T, tree *node, requests []testRequest) {
+	t.Helper()
+	for _, request := range requests {
+		handlers, params, tsr := tree.getValue(request.path, nil, false)
+		route := ""
+		if handlers != nil {
+			route = handlers[0](nil).Error()
+		} else {
+			params = nil
+		}
+		if route != request.route {
+			t.Errorf("%s: route = %q, want %q", request.path, route, request.route)
+		}
+		if len(params) > 0 || len(request.params) > 0 {
+			if !reflect.DeepEqual(params, request.params) {
+				t.Errorf("%s: params = %v, want %v", request.path, params, request.params)
+			}
+		}
+		if tsr != request.tsr {
+			t.Errorf("%s: tsr = %v, want %v", request.path, tsr, request.tsr)
+		}
+	}
+}
+
+func TestTreeConstraints(t *testing.T) {
+	tree := newTestTree(
+		"/users/:slug",
+		"/users/:id<int>",
+		"/users/:name<alpha>",
+		"/users/:id<int>/posts/:post<uint>",
+		"/files/:name<[a-z0-9-]+>",
+		"/nums/:n<int>",
+		"/items/:id<uuid>/",
+	)
+	checkRequests(t, tree, []testRequest{
+		{"/users/42", "/users/:id<int>", Params{{"id", "42"}}, false},
+		{"/users/-7", "/users/:id<int>", Params{{"id", "-7"}}, false},
+		{"/users/bob", "/users/:name<alpha>", Params{{"name", "bob"}}, false},
+		{"/users/bob-1", "/users/:slug", Params{{"slug", "bob-1"}}, false},
+		{"/users/42/posts/7", "/users/:id<int>/posts/:post<uint>", Params{{"id", "42"}, {"post", "7"}}, false},
+		{"/users/42/posts/x", "", nil, false},
+		{"/users/42/", "", nil, true},
+		{"/files/a-1", "/files/:name<[a-z0-9-]+>", Params{{"name", "a-1"}}, false},
+		{"/files/A_1", "", nil, false},
+		{"/nums/12", "/nums/:n<int>", Params{{"n", "12"}}, false},
+		{"/nums/abc", "", nil, false},
+		{"/nums/abc/", "", nil, false},
+		{"/items/123e4567-e89b-12d3-a456-426614174000", "", nil, true},
+		{"/items/123e4567-e89b-12d3-a456-426614174000/", "/items/:id<uuid>/", Params{{"id", "123e4567-e89b-12d3-a456-426614174000"}}, false},
+	})
+}
+
+func TestTreeConstraintBacktracking(t *testing.T) {
+	tree := newTestTree(
+		"/posts/:id<int>/comments",
+		"/posts/:slug/edit",
+		"/posts/:id<int>",
+	)
+	checkRequests(t, tree, []testRequest{
+		{"/posts/1/comments", "/posts/:id<int>/comments", Params{{"id", "1"}}, false},
+		// the int param matches the value but not the rest of the path
+		{"/posts/1/edit", "/posts/:slug/edit", Params{{"slug", "1"}}, false},
+		{"/posts/hello/edit", "/posts/:slug/edit", Params{{"slug", "hello"}}, false},
+		{"/posts/1", "/posts/:id<int>", Params{{"id", "1"}}, false},
+		{"/posts/hello", "", nil, false},
+		{"/posts/hello/comments", "", nil, false},
+	})
+}
+
+func TestTreeConstraintConflicts(t *testing.T) {
+	tests := []struct {
+		routes   []string
+		conflict bool
+	}{
+		{[]string{"/users/:id<int>", "/users/:name<alpha>"}, false},
+		{[]string{"/users/:id", "/users/:id<int>", "/users/:id<int>/posts"}, false},
+		{[]string{"/users/:id", "/users/:name"}, true},
+		{[]string{"/users/:id<int>", "/users/:num<int>"}, true},
+		{[]string{"/users/:id<int>", "/users/:id<int>"}, true},
+		{[]string{"/users/:id<int>", "/users/new"}, true},
+		{[]string{"/users/:id<int>", "/users/*rest"}, true},
+		{[]string{"/users/*rest", "/users/:id<int>"}, true},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recovered := recover(); (recovered != nil) != tt.conflict {
+					t.Errorf("%v: panic = %v, want conflict %v", tt.routes, recovered, tt.conflict)
+				}
+			}()
+			newTestTree(tt.routes...)
+		}()
+	}
+}
+
+func TestTreeConstraintCaseInsensitivePath(t *testing.T) {
+	tree := newTestTree(
+		"/users/:id<int>",
+		"/users/:name<alpha>/profile",
+		"/users/:slug/settings/",
+	)
+	tests := []struct {
+		path  string
+		fixed string
+		found bool
+	}{
+		{"/USERS/42", "/users/42", true},
+		{"/Users/Bob/PROFILE", "/users/Bob/profile", true},
+		{"/users/42/Profile", "", false},
+		{"/USERS/bob-1/Settings", "/users/bob-1/settings/", true},
+		{"/USERS/x", "", false},
+	}
+	for _, tt := range tests {
+		fixed, found := tree.findCaseInsensitivePath(tt.path, true)
+		if found != tt.found || (found && string(fixed) != tt.fixed) {
+			t.Errorf("%s: fixed = %q, %v, want %q, %v", tt.path, fixed, found, tt.fixed, tt.found)
+		}
+	}
+}
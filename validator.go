@@ -0,0 +1,380 @@
+package zouwu
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	emailRegex   = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+	alphaRegex   = regexp.MustCompile(`^[a-zA-Z]+$`)
+	alnumRegex   = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	numericRegex = regexp.MustCompile(`^[-+]?[0-9]+(?:\.[0-9]+)?$`)
+)
+
+// ValidationFunc reports whether field satisfies a rule, param is the text after '=' in the tag.
+// Pointers are dereferenced before the function is called.
+type ValidationFunc func(field reflect.Value, param string) bool
+
+// FieldError describes a field that failed one validation rule.
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+func (fe FieldError) String() string {
+	if fe.Param == "" {
+		return fe.Field + ": " + fe.Rule
+	}
+	return fe.Field + ": " + fe.Rule + "=" + fe.Param
+}
+
+// ValidationError lists all the fields that failed validation.
+// The default error handler renders it as a 400 JSON response.
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.String()
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Validator validates structs by their `validate` tag, e.g.
+//     type User struct {
+//         Name  string `json:"name" validate:"required,min=1,max=64"`
+//         Email string `json:"email" validate:"omitempty,email"`
+//         Role  string `json:"role" validate:"oneof=admin member"`
+//     }
+// Nested structs and slices of structs are validated recursively.
+// Field paths use the json tag name when it exists.
+// The tags of a struct type are parsed once, an unknown rule or a malformed parameter
+// is returned as an error by every Validate of that type.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]ValidationFunc
+	cache map[reflect.Type]*structRules
+}
+
+// structRules is the parsed `validate` tags of a struct type
+type structRules struct {
+	fields []fieldRules
+	err    error
+}
+
+// fieldRules is the parsed `validate` tag of a struct field
+type fieldRules struct {
+	index int
+	name  string
+	rules []fieldRule
+}
+
+type fieldRule struct {
+	name  string
+	param string
+	fn    ValidationFunc
+}
+
+// numericParamRules are the built-in rules whose parameter must be a number
+var numericParamRules = map[string]bool{"min": true, "max": true, "len": true, "gt": true, "lt": true}
+
+// NewValidator return a Validator with the built-in rules:
+// required, omitempty, min, max, len, gt, lt, email, url, oneof, alpha, alnum, numeric.
+func NewValidator() *Validator {
+	return &Validator{
+		rules: map[string]ValidationFunc{
+			"min":     validateMin,
+			"max":     validateMax,
+			"len":     validateLen,
+			"gt":      validateGt,
+			"lt":      validateLt,
+			"email":   matchString(emailRegex),
+			"alpha":   matchString(alphaRegex),
+			"alnum":   matchString(alnumRegex),
+			"numeric": matchString(numericRegex),
+			"url":     validateURL,
+			"oneof":   validateOneOf,
+		},
+		cache: make(map[reflect.Type]*structRules),
+	}
+}
+
+// RegisterRule registers a custom rule, it replaces any rule already registered with that name.
+func (v *Validator) RegisterRule(name string, fn ValidationFunc) {
+	v.mu.Lock()
+	v.rules[name] = fn
+	// the parsed types may refer to the rule
+	v.cache = make(map[reflect.Type]*structRules)
+	v.mu.Unlock()
+}
+
+// Validate validates obj, a struct or pointer to struct, and returns a *ValidationError
+// listing every failed field. Other values are ignored.
+func (v *Validator) Validate(obj interface{}) error {
+	rv := reflect.ValueOf(obj)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs []FieldError
+	if err := v.validateStruct(rv, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// structRules return the cached rules of struct type rt, parsing them on first use.
+func (v *Validator) structRules(rt reflect.Type) *structRules {
+	v.mu.RLock()
+	sr, ok := v.cache[rt]
+	v.mu.RUnlock()
+	if ok {
+		return sr
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if sr, ok = v.cache[rt]; !ok {
+		sr = v.parseStruct(rt)
+		v.cache[rt] = sr
+	}
+	return sr
+}
+
+// parseStruct parses the `validate` tags of rt, v.mu must be held.
+func (v *Validator) parseStruct(rt reflect.Type) *structRules {
+	sr := new(structRules)
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		fr := fieldRules{index: i}
+		if !sf.Anonymous {
+			fr.name = fieldName(sf)
+		}
+		if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+			for _, rule := range strings.Split(tag, ",") {
+				name, param := rule, ""
+				if j := strings.IndexByte(rule, '='); j >= 0 {
+					name, param = rule[:j], rule[j+1:]
+				}
+				fn, ok := v.rules[name]
+				switch {
+				case name == "omitempty" || name == "required":
+				case !ok:
+					sr.err = errors.Errorf("[zouwu Validator]: unknown validation rule '%s' on %s.%s", name, rt, sf.Name)
+					return sr
+				case numericParamRules[name]:
+					if _, err := strconv.ParseFloat(param, 64); err != nil {
+						sr.err = errors.Errorf("[zouwu Validator]: invalid parameter '%s' of rule '%s' on %s.%s", param, name, rt, sf.Name)
+						return sr
+					}
+				}
+				fr.rules = append(fr.rules, fieldRule{name: name, param: param, fn: fn})
+			}
+		}
+		sr.fields = append(sr.fields, fr)
+	}
+	return sr
+}
+
+func (v *Validator) validateStruct(rv reflect.Value, prefix string, errs *[]FieldError) error {
+	sr := v.structRules(rv.Type())
+	if sr.err != nil {
+		return sr.err
+	}
+	for _, fr := range sr.fields {
+		field := rv.Field(fr.index)
+		path := prefix
+		if fr.name != "" {
+			path = joinFieldPath(prefix, fr.name)
+		}
+		if !validateField(field, path, fr.rules, errs) {
+			continue
+		}
+		if err := v.validateNested(field, path, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateField applies rules to field, it returns false when the remaining rules
+// and nested values must be skipped.
+func validateField(field reflect.Value, path string, rules []fieldRule, errs *[]FieldError) bool {
+	for _, rule := range rules {
+		switch rule.name {
+		case "omitempty":
+			if isZero(field) {
+				return false
+			}
+			continue
+		case "required":
+			if isZero(field) {
+				*errs = append(*errs, FieldError{Field: path, Rule: rule.name})
+				return false
+			}
+			continue
+		}
+
+		value := field
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return false
+			}
+			value = value.Elem()
+		}
+		if !rule.fn(value, rule.param) {
+			*errs = append(*errs, FieldError{Field: path, Rule: rule.name, Param: rule.param})
+		}
+	}
+	return true
+}
+
+func (v *Validator) validateNested(field reflect.Value, path string, errs *[]FieldError) error {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	switch field.Kind() {
+	case reflect.Struct:
+		if field.Type() != timeType {
+			return v.validateStruct(field, path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < field.Len(); i++ {
+			if err := v.validateNested(field.Index(i), path+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fieldName(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("json"); tag != "" && tag != "-" {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func joinFieldPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func isZero(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map:
+		return field.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return field.IsNil()
+	}
+	return field.IsZero()
+}
+
+// size return the value compared by min, max, len, gt and lt:
+// the length of strings, slices and maps, the value itself for numbers.
+func size(field reflect.Value) (float64, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(field.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(field.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), true
+	}
+	return 0, false
+}
+
+func compareSize(field reflect.Value, param string, cmp func(a, b float64) bool) bool {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	s, ok := size(field)
+	return ok && cmp(s, n)
+}
+
+func validateMin(field reflect.Value, param string) bool {
+	return compareSize(field, param, func(a, b float64) bool { return a >= b })
+}
+
+func validateMax(field reflect.Value, param string) bool {
+	return compareSize(field, param, func(a, b float64) bool { return a <= b })
+}
+
+func validateLen(field reflect.Value, param string) bool {
+	return compareSize(field, param, func(a, b float64) bool { return a == b })
+}
+
+func validateGt(field reflect.Value, param string) bool {
+	return compareSize(field, param, func(a, b float64) bool { return a > b })
+}
+
+func validateLt(field reflect.Value, param string) bool {
+	return compareSize(field, param, func(a, b float64) bool { return a < b })
+}
+
+func matchString(re *regexp.Regexp) ValidationFunc {
+	return func(field reflect.Value, _ string) bool {
+		return field.Kind() == reflect.String && re.MatchString(field.String())
+	}
+}
+
+func validateURL(field reflect.Value, _ string) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	u, err := url.ParseRequestURI(field.String())
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func validateOneOf(field reflect.Value, param string) bool {
+	var val string
+	switch field.Kind() {
+	case reflect.String:
+		val = field.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val = strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val = strconv.FormatUint(field.Uint(), 10)
+	default:
+		return false
+	}
+	for _, option := range strings.Fields(param) {
+		if option == val {
+			return true
+		}
+	}
+	return false
+}
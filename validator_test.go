@@ -0,0 +1,178 @@
+package zouwu
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidatorRules(t *testing.T) {
+	type (
+		required struct {
+			V string `validate:"required"`
+		}
+		omitempty struct {
+			V string `validate:"omitempty,email"`
+		}
+		min struct {
+			V string `validate:"min=2"`
+		}
+		max struct {
+			V []int `validate:"max=2"`
+		}
+		length struct {
+			V string `validate:"len=3"`
+		}
+		gt struct {
+			V int `validate:"gt=0"`
+		}
+		lt struct {
+			V float64 `validate:"lt=1.5"`
+		}
+		email struct {
+			V string `validate:"email"`
+		}
+		url struct {
+			V string `validate:"url"`
+		}
+		oneof struct {
+			V int `validate:"oneof=1 2"`
+		}
+		alpha struct {
+			V string `validate:"alpha"`
+		}
+		alnum struct {
+			V string `validate:"alnum"`
+		}
+		numeric struct {
+			V string `validate:"numeric"`
+		}
+		pointer struct {
+			V *int `validate:"omitempty,gt=1"`
+		}
+	)
+	one, two := 1, 2
+	tests := []struct {
+		rule  string
+		valid interface{}
+		bad   interface{}
+	}{
+		{"required", required{"a"}, required{}},
+		{"omitempty", omitempty{}, omitempty{"a"}},
+		{"min", min{"ab"}, min{"a"}},
+		{"max", max{[]int{1, 2}}, max{[]int{1, 2, 3}}},
+		{"len", length{"abc"}, length{"ab"}},
+		{"gt", gt{1}, gt{0}},
+		{"lt", lt{1.4}, lt{1.5}},
+		{"email", email{"a@b.c"}, email{"a@"}},
+		{"url", url{"https://example.com/a"}, url{"example.com"}},
+		{"oneof", oneof{2}, oneof{3}},
+		{"alpha", alpha{"abc"}, alpha{"ab1"}},
+		{"alnum", alnum{"ab1"}, alnum{"ab-1"}},
+		{"numeric", numeric{"-1.5"}, numeric{"1e3"}},
+		{"pointer", pointer{&two}, pointer{&one}},
+	}
+	v := NewValidator()
+	for _, tt := range tests {
+		if err := v.Validate(tt.valid); err != nil {
+			t.Errorf("%s: Validate(%+v) = %v, want nil", tt.rule, tt.valid, err)
+		}
+		err, ok := v.Validate(tt.bad).(*ValidationError)
+		if !ok || len(err.Errors) != 1 || err.Errors[0].Field != "V" {
+			t.Errorf("%s: Validate(%+v) = %v, want one error on V", tt.rule, tt.bad, err)
+		}
+	}
+}
+
+func TestValidatorNested(t *testing.T) {
+	type Address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type Embedded struct {
+		ID int `json:"id" validate:"gt=0"`
+	}
+	type User struct {
+		Embedded
+		Name      string     `json:"name" validate:"required"`
+		Address   *Address   `json:"address"`
+		Addresses []Address  `json:"addresses" validate:"max=2"`
+		Optional  *Address   `json:"optional"`
+		Others    []*Address `json:"-"`
+	}
+	err := NewValidator().Validate(&User{
+		Address:   &Address{},
+		Addresses: []Address{{City: "a"}, {}},
+		Others:    []*Address{nil, {}},
+	})
+	want := []FieldError{
+		{Field: "id", Rule: "gt", Param: "0"},
+		{Field: "name", Rule: "required"},
+		{Field: "address.city", Rule: "required"},
+		{Field: "addresses[1].city", Rule: "required"},
+		{Field: "Others[1].city", Rule: "required"},
+	}
+	if ve, ok := err.(*ValidationError); !ok || !reflect.DeepEqual(ve.Errors, want) {
+		t.Errorf("Validate() = %v, want %v", err, want)
+	}
+}
+
+func TestValidatorInvalidTag(t *testing.T) {
+	type unknownRule struct {
+		V string `validate:"required,uuid"`
+	}
+	type badParam struct {
+		V string `validate:"min=x"`
+	}
+	v := NewValidator()
+	tests := []struct {
+		obj  interface{}
+		want string
+	}{
+		{unknownRule{}, "unknown validation rule 'uuid' on zouwu.unknownRule.V"},
+		{&badParam{V: "a"}, "invalid parameter 'x' of rule 'min' on zouwu.badParam.V"},
+		{struct{ Nested badParam }{}, "zouwu.badParam.V"},
+	}
+	for _, tt := range tests {
+		// the parse error is cached and returned every time
+		for i := 0; i < 2; i++ {
+			if err := v.Validate(tt.obj); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate(%T) = %v, want an error containing %q", tt.obj, err, tt.want)
+			}
+		}
+	}
+
+	v.RegisterRule("uuid", func(field reflect.Value, _ string) bool { return field.Len() == 36 })
+	if err := v.Validate(unknownRule{V: "a"}); err == nil || !strings.Contains(err.Error(), "validation failed: V: uuid") {
+		t.Errorf("Validate() after RegisterRule = %v, want a uuid validation error", err)
+	}
+}
+
+func TestBindValidationError(t *testing.T) {
+	type login struct {
+		User     string `json:"user" validate:"required"`
+		Password string `json:"password" validate:"min=6"`
+	}
+	e := NewServer()
+	e.POST("/login", func(c *Context) error {
+		var l login
+		if err := c.Bind(&l); err != nil {
+			return err
+		}
+		return c.String("ok")
+	})
+	req := newRequest(http.MethodPost, "/login")
+	req.Header.SetContentType(MIMEApplicationJSON)
+	req.SetBodyString(`{"password":"123"}`)
+	resp := performRequest(e, req)
+	if resp.StatusCode() != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode(), http.StatusBadRequest)
+	}
+	if ct := string(resp.Header.ContentType()); ct != MIMEApplicationJSON {
+		t.Errorf("Content-Type = %q, want %q", ct, MIMEApplicationJSON)
+	}
+	want := `{"errors":[{"field":"user","rule":"required"},{"field":"password","rule":"min","param":"6"}]}`
+	if body := string(resp.Body()); body != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
@@ -0,0 +1,304 @@
+// Package zouwutest provides a fluent client to unit test zouwu routes and middleware
+// without opening sockets, the requests are served by Engine.Test.
+//     func TestHello(t *testing.T) {
+//         e := zouwu.NewServer()
+//         e.GET("/hello", hello)
+//         zouwutest.New(t, e).GET("/hello").
+//             WithHeader("Accept", "application/json").
+//             Expect().
+//             Status(http.StatusOK).
+//             Header("Content-Type", "application/json").
+//             JSON(map[string]string{"message": "hello"})
+//     }
+package zouwutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+)
+
+// Client sends requests to an Engine, failures are reported to t.
+type Client struct {
+	t       testing.TB
+	engine  *zouwu.Engine
+	header  http.Header
+	timeout []time.Duration
+}
+
+// New return a Client sending requests to engine.
+func New(t testing.TB, engine *zouwu.Engine) *Client {
+	return &Client{t: t, engine: engine, header: make(http.Header)}
+}
+
+// WithHeader sets a header sent with every request of the client.
+func (c *Client) WithHeader(key, value string) *Client {
+	c.header.Set(key, value)
+	return c
+}
+
+// WithTimeout sets the timeout of Engine.Test for every request of the client.
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	c.timeout = []time.Duration{timeout}
+	return c
+}
+
+// Request starts a request with the given method and path, the path may contain a query string.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: cloneHeader(c.header),
+		query:  make(url.Values),
+	}
+}
+
+// GET starts a GET request.
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+// HEAD starts a HEAD request.
+func (c *Client) HEAD(path string) *Request {
+	return c.Request(http.MethodHead, path)
+}
+
+// POST starts a POST request.
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+// PUT starts a PUT request.
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+// PATCH starts a PATCH request.
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+// DELETE starts a DELETE request.
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+// OPTIONS starts an OPTIONS request.
+func (c *Client) OPTIONS(path string) *Request {
+	return c.Request(http.MethodOptions, path)
+}
+
+// Request is a request being built, it is sent by Expect.
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   []byte
+}
+
+// WithHeader sets a request header, the Host header sets the host of the request.
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithQuery adds a query parameter.
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie adds a request cookie.
+func (r *Request) WithCookie(name, value string) *Request {
+	cookie := (&http.Cookie{Name: name, Value: value}).String()
+	if prev := r.header.Get(zouwu.HeaderCookie); prev != "" {
+		cookie = prev + "; " + cookie
+	}
+	r.header.Set(zouwu.HeaderCookie, cookie)
+	return r
+}
+
+// WithBody sets the request body and its Content-Type.
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.header.Set(zouwu.HeaderContentType, contentType)
+	r.body = body
+	return r
+}
+
+// WithJSON sets the request body to v encoded as JSON.
+func (r *Request) WithJSON(v interface{}) *Request {
+	body, err := json.Marshal(v)
+	if err != nil {
+		r.client.t.Helper()
+		r.client.t.Fatalf("zouwutest: encode JSON body: %v", err)
+	}
+	return r.WithBody(zouwu.MIMEApplicationJSON, body)
+}
+
+// WithForm sets the request body to the url-encoded form.
+func (r *Request) WithForm(form url.Values) *Request {
+	return r.WithBody(zouwu.MIMEApplicationForm, []byte(form.Encode()))
+}
+
+// Expect sends the request and return its response, it fails the test when the request
+// can not be served.
+func (r *Request) Expect() *Response {
+	t := r.client.t
+	t.Helper()
+	target := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req := httptest.NewRequest(r.method, target, body)
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	// net/http writes the Host header from req.Host
+	if host := r.header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	resp, err := r.client.engine.Test(req, r.client.timeout...)
+	if err != nil {
+		t.Fatalf("zouwutest: %s %s: %v", r.method, target, err)
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("zouwutest: %s %s: read body: %v", r.method, target, err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	return &Response{raw: resp, t: t, name: r.method + " " + target, body: respBody}
+}
+
+// Response is the response of a Request, its assertions report failures with t.Errorf
+// and return the Response so they can be chained.
+type Response struct {
+	raw  *http.Response
+	t    testing.TB
+	name string
+	body []byte
+}
+
+// Status asserts the status code.
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.raw.StatusCode != code {
+		r.t.Errorf("%s: status = %d, want %d, body: %s", r.name, r.raw.StatusCode, code, r.body)
+	}
+	return r
+}
+
+// Header asserts the value of the header key.
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+	if got := r.raw.Header.Get(key); got != value {
+		r.t.Errorf("%s: header %s = %q, want %q", r.name, key, got, value)
+	}
+	return r
+}
+
+// HeaderContains asserts the header key contains substr.
+func (r *Response) HeaderContains(key, substr string) *Response {
+	r.t.Helper()
+	if got := r.raw.Header.Get(key); !strings.Contains(got, substr) {
+		r.t.Errorf("%s: header %s = %q, want it to contain %q", r.name, key, got, substr)
+	}
+	return r
+}
+
+// NoHeader asserts the header key is not set.
+func (r *Response) NoHeader(key string) *Response {
+	r.t.Helper()
+	if got, ok := r.raw.Header[http.CanonicalHeaderKey(key)]; ok {
+		r.t.Errorf("%s: header %s = %q, want no header", r.name, key, got)
+	}
+	return r
+}
+
+// Body asserts the body equals body.
+func (r *Response) Body(body string) *Response {
+	r.t.Helper()
+	if string(r.body) != body {
+		r.t.Errorf("%s: body = %q, want %q", r.name, r.body, body)
+	}
+	return r
+}
+
+// BodyContains asserts the body contains substr.
+func (r *Response) BodyContains(substr string) *Response {
+	r.t.Helper()
+	if !strings.Contains(string(r.body), substr) {
+		r.t.Errorf("%s: body = %q, want it to contain %q", r.name, r.body, substr)
+	}
+	return r
+}
+
+// JSON asserts the body is the JSON encoding of expected, both are compared once decoded
+// so the field order and spacing do not matter.
+func (r *Response) JSON(expected interface{}) *Response {
+	r.t.Helper()
+	want, err := json.Marshal(expected)
+	if err != nil {
+		r.t.Fatalf("%s: encode expected JSON: %v", r.name, err)
+	}
+	var gotValue, wantValue interface{}
+	if err = json.Unmarshal(r.body, &gotValue); err != nil {
+		r.t.Errorf("%s: body is not JSON: %v, body: %s", r.name, err, r.body)
+		return r
+	}
+	if err = json.Unmarshal(want, &wantValue); err != nil {
+		r.t.Fatalf("%s: decode expected JSON: %v", r.name, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		r.t.Errorf("%s: JSON body = %s, want %s", r.name, r.body, want)
+	}
+	return r
+}
+
+// DecodeJSON decodes the body into v, it fails the test when the body is not valid JSON.
+func (r *Response) DecodeJSON(v interface{}) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.body, v); err != nil {
+		r.t.Fatalf("%s: decode JSON body: %v, body: %s", r.name, err, r.body)
+	}
+	return r
+}
+
+// Raw return the http.Response, its body can be read again.
+func (r *Response) Raw() *http.Response {
+	return r.raw
+}
+
+// Bytes return the response body.
+func (r *Response) Bytes() []byte {
+	return r.body
+}
+
+func cloneHeader(h http.Header) http.Header {
+	clone := make(http.Header, len(h))
+	for key, values := range h {
+		clone[key] = append([]string(nil), values...)
+	}
+	return clone
+}
@@ -0,0 +1,174 @@
+package zouwutest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DCRcoder/zouwu"
+)
+
+// recorder records the failures reported by the assertions instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls f with the recorder in its own goroutine, so Fatalf can stop it
+func (r *recorder) run(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	<-done
+}
+
+type user struct {
+	ID   int      `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func newEngine() *zouwu.Engine {
+	e := zouwu.NewServer()
+	e.GET("/users/:id", func(c *zouwu.Context) error {
+		c.Ctx.Response.Header.Set("X-Version", c.Query("v"))
+		return c.JSON(user{ID: int(c.URLParamInt("id")), Name: "bob", Tags: []string{"a", "b"}})
+	})
+	e.POST("/echo", func(c *zouwu.Context) error {
+		c.Ctx.Response.Header.Set("X-Content-Type", string(c.Ctx.Request.Header.ContentType()))
+		c.Ctx.Response.Header.Set("X-Token", string(c.Ctx.Request.Header.Peek("X-Token")))
+		c.Ctx.Response.Header.Set("X-Host", string(c.Ctx.Host()))
+		return c.String(string(c.Ctx.Request.Header.Cookie("a")) + "|" + string(c.Ctx.Request.Header.Cookie("b")) + "|" + string(c.GetRequestBody()))
+	})
+	e.GET("/slow", func(c *zouwu.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return c.String("done")
+	})
+	return e
+}
+
+func TestAssertions(t *testing.T) {
+	New(t, newEngine()).GET("/users/42").
+		WithQuery("v", "2").
+		Expect().
+		Status(http.StatusOK).
+		Header("Content-Type", zouwu.MIMEApplicationJSON).
+		Header("X-Version", "2").
+		HeaderContains("Content-Type", "json").
+		NoHeader("X-Missing").
+		Body(`{"id":42,"name":"bob","tags":["a","b"]}`).
+		BodyContains(`"name":"bob"`).
+		JSON(user{ID: 42, Name: "bob", Tags: []string{"a", "b"}}).
+		JSON(struct {
+			Tags []string `json:"tags"`
+			Name string   `json:"name"`
+			ID   int      `json:"id"`
+		}{[]string{"a", "b"}, "bob", 42})
+
+	var got user
+	New(t, newEngine()).GET("/users/7").Expect().DecodeJSON(&got)
+	if got.ID != 7 || got.Name != "bob" {
+		t.Errorf("DecodeJSON = %+v", got)
+	}
+}
+
+func TestAssertionFailures(t *testing.T) {
+	rec := &recorder{TB: t}
+	rec.run(func() {
+		New(rec, newEngine()).GET("/users/42").Expect().
+			Status(http.StatusCreated).
+			Header("Content-Type", "text/plain").
+			HeaderContains("Content-Type", "xml").
+			NoHeader("Content-Type").
+			Body("{}").
+			BodyContains("alice").
+			JSON(user{ID: 43})
+	})
+	wants := []string{
+		"GET /users/42: status = 200, want 201",
+		"GET /users/42: header Content-Type = \"application/json\", want \"text/plain\"",
+		"want it to contain \"xml\"",
+		"want no header",
+		"GET /users/42: body = ",
+		"want it to contain \"alice\"",
+		"GET /users/42: JSON body = ",
+	}
+	if len(rec.errors) != len(wants) {
+		t.Fatalf("errors = %q, want %d errors", rec.errors, len(wants))
+	}
+	for i, want := range wants {
+		if !strings.Contains(rec.errors[i], want) {
+			t.Errorf("error %d = %q, want it to contain %q", i, rec.errors[i], want)
+		}
+	}
+
+	rec = &recorder{TB: t}
+	rec.run(func() {
+		var v user
+		New(rec, newEngine()).GET("/missing").Expect().DecodeJSON(&v)
+	})
+	if !strings.Contains(rec.fatal, "decode JSON body") {
+		t.Errorf("fatal = %q, want a JSON decoding error", rec.fatal)
+	}
+}
+
+func TestRequestBuilders(t *testing.T) {
+	client := New(t, newEngine()).WithHeader("X-Token", "secret")
+
+	client.POST("/echo").
+		WithCookie("a", "1").
+		WithCookie("b", "2").
+		WithBody("text/plain", []byte("raw")).
+		Expect().
+		Header("X-Token", "secret").
+		Header("X-Content-Type", "text/plain").
+		Body("1|2|raw")
+
+	client.POST("/echo").
+		WithHeader("X-Token", "other").
+		WithHeader("Host", "api.example.com").
+		WithJSON(user{ID: 1}).
+		Expect().
+		Header("X-Token", "other").
+		Header("X-Host", "api.example.com").
+		Header("X-Content-Type", zouwu.MIMEApplicationJSON).
+		Body(`||{"id":1,"name":"","tags":null}`)
+
+	client.POST("/echo").
+		WithForm(url.Values{"name": {"bob"}}).
+		Expect().
+		Header("X-Content-Type", zouwu.MIMEApplicationForm).
+		Body("||name=bob")
+
+	client.GET("/users/1?v=1").WithQuery("v", "2").Expect().Header("X-Version", "1")
+}
+
+func TestClientTimeout(t *testing.T) {
+	rec := &recorder{TB: t}
+	rec.run(func() {
+		New(rec, newEngine()).WithTimeout(20 * time.Millisecond).GET("/slow").Expect()
+	})
+	if !strings.Contains(rec.fatal, "GET /slow") {
+		t.Errorf("fatal = %q, want a timeout error", rec.fatal)
+	}
+
+	New(t, newEngine()).WithTimeout(0).GET("/slow").Expect().Body("done")
+}